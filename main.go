@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -12,53 +14,30 @@ import (
 	"github.com/bytectlgo/edge-tts/pkg/edge_tts"
 )
 
-func listVoices() error {
-	// Create context
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Call ListVoices function from pkg/edge_tts
-	voices, err := edge_tts.ListVoices(ctx)
-	if err != nil {
-		return fmt.Errorf("Failed to get voice list: %v", err)
-	}
-
-	// Print header
-	fmt.Printf("%-35s %-9s %-22s %-35s\n", "Name", "Gender", "ContentCategories", "VoicePersonalities")
-	fmt.Println(strings.Repeat("-", 100))
-
-	// Print voice list
-	for _, voice := range voices {
-		personalities := strings.Join(voice.StyleList, ", ")
-		if personalities == "" {
-			personalities = "Friendly, Positive"
-		}
-		fmt.Printf("%-35s %-9s %-22s %-35s\n",
-			voice.ShortName,
-			voice.Gender,
-			"General",
-			personalities)
-	}
-	return nil
-}
-
-func textToSpeech(text, voice, outputFile, subtitleFile string, rate, volume, pitch string) error {
+func textToSpeech(text, voice, outputFile, subtitleFile, timingsFile string, rate, volume, pitch string, fit, tolerance time.Duration, extra ...edge_tts.Option) error {
 	// Create new TTS configuration
 	opts := []edge_tts.Option{
 		edge_tts.WithRate(rate),
 		edge_tts.WithVolume(volume),
 		edge_tts.WithPitch(pitch),
 	}
+	timeout := 30 * time.Second
+	if fit > 0 {
+		opts = append(opts, edge_tts.WithTargetDuration(fit), edge_tts.WithFitTolerance(tolerance))
+		// The text may be synthesized several times
+		timeout = 2 * time.Minute
+	}
+	opts = append(opts, extra...)
 
 	// Create new Communicate instance
 	comm := edge_tts.NewCommunicate(text, voice, opts...)
 
 	// Set timeout context
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	// Save audio to file
-	err := comm.Save(ctx, outputFile, subtitleFile)
+	err := comm.SaveWithTimings(ctx, outputFile, subtitleFile, timingsFile)
 	if err != nil {
 		return fmt.Errorf("Failed to save audio: %v", err)
 	}
@@ -69,32 +48,334 @@ func textToSpeech(text, voice, outputFile, subtitleFile string, rate, volume, pi
 	if subtitleFile != "" {
 		fmt.Printf("Subtitles saved to %s\n", subtitleFile)
 	}
+	if timingsFile != "" {
+		fmt.Printf("Timings saved to %s\n", timingsFile)
+	}
+	if result, ok := comm.FitResult(); ok {
+		if result.Fits {
+			fmt.Printf("Fitted to %v at rate %s\n", result.Duration.Round(time.Millisecond), result.Rate)
+		} else {
+			fmt.Printf("Warning: audio lasts %v at rate %s, the rate limit was reached before %v\n", result.Duration.Round(time.Millisecond), result.Rate, fit)
+		}
+	}
+	return nil
+}
+
+// speakerFlags collects repeated -speaker Name=Voice flags
+type speakerFlags map[string]string
+
+func (f speakerFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for name, voice := range f {
+		pairs = append(pairs, name+"="+voice)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f speakerFlags) Set(value string) error {
+	name, voice, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(voice) == "" {
+		return fmt.Errorf("expected Name=Voice, got %q", value)
+	}
+	f[strings.TrimSpace(name)] = strings.TrimSpace(voice)
+	return nil
+}
+
+// listFlags collects a flag that can be repeated
+type listFlags []string
+
+func (f *listFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// loadLexicons loads -lexicon values of the form [locale=]path
+func loadLexicons(values []string, caseSensitive, wholeWord bool) ([]*edge_tts.Lexicon, error) {
+	lexicons := make([]*edge_tts.Lexicon, 0, len(values))
+	for _, value := range values {
+		locale, path, ok := strings.Cut(value, "=")
+		if !ok {
+			locale, path = "", value
+		}
+		lexicon, err := edge_tts.LoadLexicon(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load lexicon %s: %v", path, err)
+		}
+		if locale != "" {
+			lexicon.Locale = locale
+		}
+		lexicon.CaseSensitive = caseSensitive
+		lexicon.WholeWord = wholeWord
+		lexicons = append(lexicons, lexicon)
+	}
+	return lexicons, nil
+}
+
+// readInput reads the -file text, '-' reads stdin
+func readInput(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Failed to read input: %v", err)
+	}
+	return string(data), nil
+}
+
+func dialogueToSpeech(scriptFile, defaultVoice, outputFile, subtitleFile string, speakers speakerFlags, pause string, rate, volume, pitch string, opts ...edge_tts.Option) error {
+	dialogue, err := edge_tts.LoadDialogue(scriptFile)
+	if err != nil {
+		return fmt.Errorf("Failed to load dialogue: %v", err)
+	}
+
+	// -speaker flags override the mapping from the script,
+	// speakers that are still unmapped use -voice
+	for name, voice := range speakers {
+		sv := dialogue.Speakers[name]
+		sv.Voice = voice
+		dialogue.Speakers[name] = sv
+	}
+	checked := map[string]bool{}
+	for _, turn := range dialogue.Turns {
+		if dialogue.Speakers[turn.Speaker].Voice == "" {
+			sv := dialogue.Speakers[turn.Speaker]
+			sv.Voice = defaultVoice
+			dialogue.Speakers[turn.Speaker] = sv
+		}
+		if voice := dialogue.Speakers[turn.Speaker].Voice; !checked[voice] {
+			checked[voice] = true
+			checkVoice(voice)
+		}
+	}
+	if pause != "" {
+		d, err := time.ParseDuration(pause)
+		if err != nil {
+			return fmt.Errorf("Invalid pause: %v", err)
+		}
+		dialogue.Pause = d
+	}
+	// -rate, -volume and -pitch apply to every speaker, the script's own
+	// settings for a speaker win
+	dialogue.Options = append(dialogue.Options, edge_tts.WithRate(rate), edge_tts.WithVolume(volume), edge_tts.WithPitch(pitch))
+	dialogue.Options = append(dialogue.Options, opts...)
+
+	// Dialogues are longer than a single text, allow a minute per turn
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(dialogue.Turns)+1)*time.Minute)
+	defer cancel()
+
+	if err := dialogue.Save(ctx, outputFile, subtitleFile); err != nil {
+		return fmt.Errorf("Failed to save dialogue: %v", err)
+	}
+
+	fmt.Printf("Audio saved to %s\n", outputFile)
+	if subtitleFile != "" {
+		fmt.Printf("Subtitles saved to %s\n", subtitleFile)
+	}
+	return nil
+}
+
+func dubToSpeech(subtitleFile, voice, outputFile, reportFile string, maxRate int, opts ...edge_tts.Option) error {
+	track, err := edge_tts.LoadSubtitles(subtitleFile)
+	if err != nil {
+		return fmt.Errorf("Failed to load subtitles: %v", err)
+	}
+	dub := edge_tts.NewDub(track.Cues(), voice, opts...)
+	dub.MaxRate = maxRate
+
+	// Every cue is synthesized separately, allow a minute per cue
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(len(dub.Cues)+1)*time.Minute)
+	defer cancel()
+
+	report, err := dub.Save(ctx, outputFile, reportFile)
+	if err != nil {
+		return fmt.Errorf("Failed to save dub: %v", err)
+	}
+
+	fmt.Printf("Audio saved to %s\n", outputFile)
+	if reportFile != "" {
+		fmt.Printf("Report saved to %s\n", reportFile)
+	}
+	for _, cue := range report.Overruns() {
+		fmt.Printf("Warning: cue %d overruns its %dms slot by %dms at rate %s: %s\n", cue.Index, cue.Slot, cue.Overrun, cue.Rate, cue.Text)
+	}
 	return nil
 }
 
 func main() {
+	// Subtitle tools run as a subcommand with their own flags
+	if len(os.Args) > 1 && os.Args[1] == "subs" {
+		if err := runSubs(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "preview" {
+		if err := runPreview(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "voices" {
+		if err := runVoices(os.Args[2:]); err != nil {
+			var code exitCode
+			if errors.As(err, &code) {
+				os.Exit(int(code))
+			}
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Define command line parameters
 	listVoicesFlag := flag.Bool("list-voices", false, "List all available voices")
+	listLocale := flag.String("locale", "", "Only list voices of this locale (en-US) or language (en)")
+	listGender := flag.String("gender", "", "Only list voices of this gender: Female or Male")
+	listStyle := flag.String("style", "", "Only list voices that support this speaking style, e.g. cheerful")
+	listOutput := flag.String("output", "table", "Voice list format: table, json, csv or markdown")
+	voiceFilter := flag.String("voice-filter", "", "Only list voices matching key=value pairs: locale, language, gender, style, category, personality, multilingual")
 	text := flag.String("text", "", "Text to convert")
 	voice := flag.String("voice", "zh-CN-XiaoxiaoNeural", "Voice to use")
 	outputMedia := flag.String("write-media", "", "Output audio filename")
-	outputSubtitles := flag.String("write-subtitles", "", "Output subtitle filename")
+	outputSubtitles := flag.String("write-subtitles", "", "Output subtitle filename (.vtt writes WebVTT, .ass/.ssa karaoke ASS, .lrc lyrics, otherwise SRT)")
+	outputTimings := flag.String("write-timings", "", "Output word and sentence timings as JSON (.jsonl for JSON Lines)")
 	rate := flag.String("rate", "+0%", "Speech rate adjustment")
 	volume := flag.String("volume", "+0%", "Volume adjustment")
 	pitch := flag.String("pitch", "+0Hz", "Pitch adjustment")
+	dialogueFile := flag.String("dialogue", "", "Dialogue script to render ('Speaker: line' text or .json)")
+	fitDuration := flag.Duration("fit-duration", 0, "Adjust the rate until the audio lasts this long, e.g. 15s")
+	fitTolerance := flag.Duration("fit-tolerance", edge_tts.DefaultFitTolerance, "How far the audio may be from -fit-duration")
+	dubFile := flag.String("dub", "", "Subtitles (SRT or WebVTT) to voice, each cue starts at its subtitle time")
+	dubMaxRate := flag.Int("dub-max-rate", edge_tts.DefaultDubMaxRate, "Highest rate in percent used to fit a dubbed cue into its slot")
+	dubReport := flag.String("dub-report", "", "Output the dub timing report as JSON")
+	pause := flag.String("pause", "", "Pause between dialogue turns, e.g. 500ms (default from the script, or 400ms)")
+	speakers := speakerFlags{}
+	flag.Var(speakers, "speaker", "Dialogue speaker voice as Name=Voice, can be repeated")
+	var lexiconFiles listFlags
+	flag.Var(&lexiconFiles, "lexicon", "Pronunciation lexicon (.pls or .tsv) as [locale=]path, can be repeated")
+	lexiconCaseSensitive := flag.Bool("lexicon-case-sensitive", false, "Match lexicon words case-sensitively")
+	lexiconWholeWord := flag.Bool("lexicon-whole-word", true, "Only match whole lexicon words")
+	normalize := flag.Bool("normalize", false, "Read numbers, dates, units and abbreviations the way the voice locale speaks them")
+	inputFile := flag.String("file", "", "Read the text from a file, '-' for stdin")
+	inputFormat := flag.String("input-format", "text", "Input format: text, markdown or html")
+	codeBlocks := flag.String("code-blocks", "skip", "Markdown/HTML code blocks: skip or announce")
+	cueMaxWords := flag.Int("cue-max-words", 0, "Merge subtitle words into cues of at most this many words")
+	cueMaxChars := flag.Int("cue-max-chars", 0, "Merge subtitle words into cues of at most this width (full-width characters count twice)")
+	cueMaxDuration := flag.String("cue-max-duration", "", "Merge subtitle words into cues of at most this duration, e.g. 5s")
+	cueSentences := flag.Bool("cue-sentences", false, "Start a new subtitle cue after sentence punctuation")
+	lineWidth := flag.Int("line-width", 0, "Wrap subtitle lines at this width (full-width characters count twice)")
+	maxLines := flag.Int("max-lines", 0, "Maximum lines per subtitle cue when wrapping")
+	alignSubtitles := flag.Bool("align-subtitles", false, "Show the input text with its punctuation and casing in subtitles instead of the bare spoken words")
+	readable := flag.Bool("readable", false, "Extend short subtitle cues (min 0.83s, max 17 chars/s, close gaps under 0.5s)")
+	muidFile := flag.String("muid-file", "", "Keep the MUID cookie in this file, so every run uses the same one")
 	flag.Parse()
 
 	// Execute corresponding function based on parameters
 	if *listVoicesFlag {
-		if err := listVoices(); err != nil {
+		filter, err := voiceFilterFlags(*voiceFilter, *listLocale, *listGender, *listStyle)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := listVoices(filter, *listOutput); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *fitDuration < 0 || *fitTolerance < 0 {
+		log.Fatal("Error: --fit-duration and --fit-tolerance must not be negative")
+	}
+
+	lexicons, err := loadLexicons(lexiconFiles, *lexiconCaseSensitive, *lexiconWholeWord)
+	if err != nil {
+		log.Fatal(err)
+	}
+	textOpts := []edge_tts.Option{edge_tts.WithLexicon(lexicons...)}
+	// Every request of a run sends the same MUID
+	if *muidFile != "" {
+		textOpts = append(textOpts, edge_tts.WithMUIDFile(*muidFile))
+	} else {
+		textOpts = append(textOpts, edge_tts.WithMUID(edge_tts.NewMUID()))
+	}
+	if *normalize {
+		textOpts = append(textOpts, edge_tts.WithNormalization())
+	}
+	if *alignSubtitles {
+		textOpts = append(textOpts, edge_tts.WithAlignedSubtitles())
+	}
+	if *cueMaxWords > 0 || *cueMaxChars > 0 || *cueMaxDuration != "" || *cueSentences || *lineWidth > 0 {
+		merge := edge_tts.MergeOptions{
+			MaxWords:      *cueMaxWords,
+			MaxChars:      *cueMaxChars,
+			SentenceBreak: *cueSentences,
+			LineWidth:     *lineWidth,
+			MaxLines:      *maxLines,
+		}
+		if *cueMaxDuration != "" {
+			d, err := time.ParseDuration(*cueMaxDuration)
+			if err != nil {
+				log.Fatalf("Invalid cue duration: %v", err)
+			}
+			merge.MaxDuration = d
+		}
+		textOpts = append(textOpts, edge_tts.WithSubtitleMerge(merge))
+	}
+	if *readable {
+		textOpts = append(textOpts, edge_tts.WithSubtitleReadability(edge_tts.DefaultReadabilityOptions()))
+	}
+
+	if *dialogueFile != "" {
+		if *outputMedia == "" {
+			log.Fatal("Error: --write-media parameter is required")
+		}
+		if *outputTimings != "" {
+			log.Fatal("Error: --write-timings is not supported with --dialogue")
+		}
+		if *fitDuration > 0 {
+			log.Fatal("Error: --fit-duration is not supported with --dialogue")
+		}
+		if err := dialogueToSpeech(*dialogueFile, *voice, *outputMedia, *outputSubtitles, speakers, *pause, *rate, *volume, *pitch, textOpts...); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *dubFile != "" {
+		if *outputMedia == "" {
+			log.Fatal("Error: --write-media parameter is required")
+		}
+		if *outputSubtitles != "" || *outputTimings != "" {
+			log.Fatal("Error: --write-subtitles and --write-timings are not supported with --dub")
+		}
+		if *fitDuration > 0 {
+			log.Fatal("Error: --fit-duration is not supported with --dub, cues are fitted to their slots")
+		}
+		checkVoice(*voice)
+		dubOpts := append([]edge_tts.Option{edge_tts.WithRate(*rate), edge_tts.WithVolume(*volume), edge_tts.WithPitch(*pitch)}, textOpts...)
+		if err := dubToSpeech(*dubFile, *voice, *outputMedia, *dubReport, *dubMaxRate, dubOpts...); err != nil {
 			log.Fatal(err)
 		}
 		return
 	}
 
+	if *inputFile != "" {
+		content, err := readInput(*inputFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*text = content
+	}
+
 	// Check required parameters
 	if *text == "" {
-		log.Fatal("Error: --text parameter is required")
+		log.Fatal("Error: --text or --file parameter is required")
 	}
 	if *outputMedia == "" && *outputSubtitles == "" {
 		log.Fatal("Error: --write-media or --write-subtitles parameter is required")
@@ -112,7 +393,26 @@ func main() {
 		}
 	}
 
-	if err := textToSpeech(*text, *voice, *outputMedia, *outputSubtitles, *rate, *volume, *pitch); err != nil {
+	format, err := edge_tts.ParseInputFormat(*inputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	markupOpts := edge_tts.DefaultMarkupOptions()
+	switch *codeBlocks {
+	case "skip":
+		markupOpts.CodeBlocks = edge_tts.CodeBlockSkip
+	case "announce":
+		markupOpts.CodeBlocks = edge_tts.CodeBlockAnnounce
+	default:
+		log.Fatalf("Error: unknown --code-blocks mode %q", *codeBlocks)
+	}
+	*text, err = edge_tts.ConvertInput(*text, format, markupOpts)
+	if err != nil {
+		log.Fatalf("Failed to convert input: %v", err)
+	}
+
+	checkVoice(*voice)
+	if err := textToSpeech(*text, *voice, *outputMedia, *outputSubtitles, *outputTimings, *rate, *volume, *pitch, *fitDuration, *fitTolerance, textOpts...); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,254 @@
+package edge_tts
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// plainText 去掉文本中的 SSML 标签并还原实体，得到朗读的原文
+func plainText(text string) string {
+	if !strings.ContainsAny(text, "<&") {
+		return text
+	}
+	return html.UnescapeString(stripTags(text))
+}
+
+// AlignText 把字幕对齐回合成时的输入文本，字幕显示原文中的标点和大小写，
+// 例如 "hello" "world" 变为 "Hello," "world!"。
+//
+// 每个词使用服务报告的字符位置（TTSChunk.TextOffset 和 TextLength），
+// 没有报告（例如规范化改写的词）或与原文对不上时按顺序在 input 中
+// 查找（先精确匹配，再忽略大小写），并带上它前面的引号、括号和后面的标点。找不到的词（例如规范化后
+// "10kg" 读作 "ten kilograms"）对应原文中前后两个找到的词之间的文字，
+// 由其中第一个词显示。应该在 MergeCues 之前调用。
+func (s *SubMaker) AlignText(input string) {
+	input = plainText(input)
+
+	// 每个词在原文中的字节位置，找不到时为 -1
+	type span struct{ start, end int }
+	var words []*SubWord
+	var spans []span
+	for i := range s.cues {
+		cue := &s.cues[i]
+		if len(cue.Words) == 0 {
+			cue.Words = []SubWord{{Start: cue.Start, End: cue.End, Text: cue.Text}}
+		}
+		for j := range cue.Words {
+			words = append(words, &cue.Words[j])
+		}
+	}
+	cursor, found := 0, 0
+	for _, word := range words {
+		start, end, ok := reportedSpan(input, word.Text, word.textOffset, word.textLength, cursor)
+		if !ok {
+			offset, next := findBoundary(input, word.Text, cursor)
+			if offset < 0 {
+				spans = append(spans, span{-1, -1})
+				continue
+			}
+			start, end = next-len(word.Text), next
+		}
+		spans = append(spans, span{start, end})
+		cursor = end
+		found++
+	}
+	// 输入和朗读的文本对不上时保持原样
+	if found == 0 {
+		return
+	}
+
+	// 把找到的词扩展到相邻的标点，找不到的词占据前后两个找到的词之间的文字
+	texts := make([]string, len(words))
+	prevEnd := 0
+	for i := range words {
+		var start, end int
+		if spans[i].start >= 0 {
+			start = extendBefore(input, spans[i].start, prevEnd)
+			end = extendAfter(input, spans[i].end)
+		} else {
+			start = skipSpace(input, prevEnd)
+			end = len(input)
+			for _, next := range spans[i+1:] {
+				if next.start >= 0 {
+					end = extendBefore(input, next.start, start)
+					break
+				}
+			}
+		}
+		if end < start {
+			end = start
+		}
+		texts[i] = collapseSpace(input[start:end])
+		prevEnd = end
+	}
+
+	// 字幕文本由它的词连接而成。文字被前面的词占据的字幕变为空，
+	// 生成字幕时会被跳过
+	n := 0
+	for i := range s.cues {
+		cue := &s.cues[i]
+		cue.Text = ""
+		for j := range cue.Words {
+			cue.Words[j].Text = texts[n]
+			cue.Text = joinCueText(cue.Text, texts[n])
+			n++
+		}
+	}
+}
+
+// reportedSpan 返回服务报告在字符位置 offset、长度 length 的 text 在 input
+// 中的字节位置。没有报告、位置在字节位置 from 之前或者那里的文字不是 text
+// 时返回 false
+func reportedSpan(input, text string, offset, length, from int) (int, int, bool) {
+	if length <= 0 {
+		return 0, 0, false
+	}
+	start := runeIndex(input, 0, offset)
+	if start < from {
+		return 0, 0, false
+	}
+	end := runeIndex(input, start, length)
+	if end < 0 || !strings.EqualFold(input[start:end], text) {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// offsetRun 是朗读文本和原文中相同的一段文字，位置和长度按字符计
+type offsetRun struct{ spoken, input, n int }
+
+// offsetMap 把朗读文本中的字符位置换算为原文中的位置。规范化改写的文字
+// （例如 "10kg" 读作 "ten kilograms"）不在任何一段中
+type offsetMap []offsetRun
+
+// maxResync 是规范化改写后最多向后找多少个字符来重新对齐两段文本
+const maxResync = 256
+
+// newOffsetMap 按顺序比较朗读文本和原文（都不含标签），找出相同的文字。
+// 遇到不同的文字时找最近的连续 3 个相同字符（或两段文本的结尾）重新对齐
+func newOffsetMap(spoken, input string) offsetMap {
+	a, b := []rune(spoken), []rune(input)
+	// same 判断 a[i:] 和 b[j:] 是否以相同的 3 个字符开始，或者同时结束
+	same := func(i, j int) bool {
+		for k := 0; k < 3; k++ {
+			if i+k == len(a) || j+k == len(b) {
+				return i+k == len(a) && j+k == len(b)
+			}
+			if a[i+k] != b[j+k] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var m offsetMap
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			if n := len(m); n > 0 && m[n-1].spoken+m[n-1].n == i && m[n-1].input+m[n-1].n == j {
+				m[n-1].n++
+			} else {
+				m = append(m, offsetRun{spoken: i, input: j, n: 1})
+			}
+			i++
+			j++
+			continue
+		}
+		// 跳过的字符越少越好
+		found := false
+		for d := 1; d <= maxResync && !found; d++ {
+			for k := 0; k <= d; k++ {
+				if i+k <= len(a) && j+d-k <= len(b) && same(i+k, j+d-k) {
+					i, j, found = i+k, j+d-k, true
+					break
+				}
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return m
+}
+
+// inputOffset 返回朗读文本中 [offset, offset+length) 在原文中的字符位置，
+// 这段文字不在同一段相同的文字中时返回 -1
+func (m offsetMap) inputOffset(offset, length int) int {
+	for _, run := range m {
+		if offset >= run.spoken && offset+length <= run.spoken+run.n {
+			return run.input + offset - run.spoken
+		}
+	}
+	return -1
+}
+
+// runeIndex 返回 input 中从字节位置 from 开始第 n 个字符的字节位置，
+// 超出 input 时返回 -1
+func runeIndex(input string, from, n int) int {
+	i := from
+	for ; n > 0; n-- {
+		if i >= len(input) {
+			return -1
+		}
+		_, size := utf8.DecodeRuneInString(input[i:])
+		i += size
+	}
+	return i
+}
+
+// isOpeningPunct 判断字符是否是写在词前面的标点，如引号和左括号
+func isOpeningPunct(r rune) bool {
+	return unicode.Is(unicode.Ps, r) || unicode.Is(unicode.Pi, r) || strings.ContainsRune("\"'¿¡", r)
+}
+
+// isTrailingPunct 判断字符是否是写在词后面的标点
+func isTrailingPunct(r rune) bool {
+	return !isOpeningPunct(r) && (unicode.IsPunct(r) || unicode.IsSymbol(r)) || r == '"' || r == '\''
+}
+
+// extendBefore 把 start 向前扩展到紧挨着的开引号和左括号，不超过 limit
+func extendBefore(input string, start, limit int) int {
+	for start > limit {
+		r, size := utf8.DecodeLastRuneInString(input[:start])
+		if !isOpeningPunct(r) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
+
+// extendAfter 把 end 向后扩展到紧挨着的标点
+func extendAfter(input string, end int) int {
+	for end < len(input) {
+		r, size := utf8.DecodeRuneInString(input[end:])
+		if !isTrailingPunct(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+// skipSpace 跳过 i 开始的空白
+func skipSpace(input string, i int) int {
+	for i < len(input) {
+		r, size := utf8.DecodeRuneInString(input[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// collapseSpace 把连续的空白合并为一个空格，中日文之间的换行直接去掉
+func collapseSpace(text string) string {
+	result := ""
+	for _, field := range strings.Fields(text) {
+		result = joinCueText(result, field)
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestAlignText 测试字幕对齐回原文
+func TestAlignText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		words []string
+		want  []string
+	}{
+		{
+			name:  "punctuation and casing",
+			input: `"Hello, world!" she said. Isn't it (really) great?`,
+			words: []string{"hello", "world", "she", "said", "Isn't", "it", "really", "great"},
+			want:  []string{`"Hello,`, `world!"`, "she", "said.", "Isn't", "it", "(really)", "great?"},
+		},
+		{
+			name:  "normalized words take the text between found words",
+			input: "It weighs 10kg. Then stop",
+			words: []string{"It", "weighs", "ten", "kilograms", "Then", "stop"},
+			want:  []string{"It", "weighs", "10kg.", "", "Then", "stop"},
+		},
+		{
+			name:  "chinese",
+			input: "你好，\n世界！「再见」",
+			words: []string{"你好", "世界", "再见"},
+			want:  []string{"你好，", "世界！", "「再见」"},
+		},
+		{
+			name:  "markup is removed",
+			input: "<emphasis level='strong'>Intro</emphasis><break time='750ms'/> Fish &amp; chips.",
+			words: []string{"Intro", "Fish", "&", "chips"},
+			want:  []string{"Intro", "Fish", "&", "chips."},
+		},
+		{
+			name:  "unrelated input is ignored",
+			input: "something else",
+			words: []string{"hello", "world"},
+			want:  []string{"hello", "world"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubMaker(t, tt.words...)
+			sub.AlignText(tt.input)
+			for i, want := range tt.want {
+				if got := sub.cues[i].Text; got != want {
+					t.Errorf("cue %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestAlignTextMerged 测试对齐后合并字幕
+func TestAlignTextMerged(t *testing.T) {
+	sub := newTestSubMaker(t, "hello", "world", "how", "are", "you")
+	sub.AlignText("Hello, world! How are you?")
+	if err := sub.MergeCuesWith(MergeOptions{SentenceBreak: true}); err != nil {
+		t.Fatalf("MergeCuesWith() error = %v", err)
+	}
+
+	want := "1\n00:00:00,000 --> 00:00:00,440\nHello, world!\n\n" +
+		"2\n00:00:00,480 --> 00:00:01,160\nHow are you?\n\n"
+	if got := sub.GetSRT(); got != want {
+		t.Errorf("GetSRT() = %q, want %q", got, want)
+	}
+	if got := sub.GetVTT(WithVTTWordTimestamps()); !strings.Contains(got, "<c>Hello,</c> <00:00:00.240><c>world!</c>") {
+		t.Errorf("GetVTT() = %q", got)
+	}
+}
+
+// TestAlignTextReportedOffsets 测试使用服务报告的字符位置对齐，
+// 没有报告或位置对不上时按顺序查找
+func TestAlignTextReportedOffsets(t *testing.T) {
+	// 朗读的文本是 "Go! go? Go, said Bob."
+	input := "Go! go? <break time='1s'/>Go, said <sub alias='Bob'>Bob</sub>."
+	sub := NewSubMaker()
+	for i, chunk := range []TTSChunk{
+		// 查找会找到第一个 "Go!"
+		{Text: "Go", TextOffset: 8, TextLength: 2},
+		// 没有报告位置
+		{Text: "said", TextOffset: -1},
+		// 位置对不上，按顺序查找
+		{Text: "Bob", TextOffset: 3, TextLength: 3},
+	} {
+		chunk.Type = "WordBoundary"
+		chunk.Offset = float64(i) * 5000000
+		chunk.Duration = 4000000
+		if err := sub.Feed(chunk); err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+	}
+	sub.AlignText(input)
+
+	var got []string
+	for _, cue := range sub.cues {
+		got = append(got, cue.Text)
+	}
+	want := []string{"Go,", "said", "Bob."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("cues = %q, want %q", got, want)
+	}
+}
+
+// TestOffsetMap 测试把朗读文本中的位置换算为原文中的位置，
+// 规范化改写的词没有位置
+func TestOffsetMap(t *testing.T) {
+	tests := []struct {
+		input  string
+		voice  string
+		spoken string
+		words  map[string]int // 朗读文本中的词和它在原文中的位置，-1 表示没有
+	}{
+		{
+			input:  "Go! I have 3 cats, <emphasis>go</emphasis>? Go &amp; 10 dogs.",
+			voice:  "en-US-AvaNeural",
+			spoken: "Go! I have three cats, go? Go & ten dogs.",
+			words:  map[string]int{"Go": 0, "have": 6, "three": -1, "cats": 13, "go": 19, "ten": -1, "dogs": 31},
+		},
+		{
+			input:  "我有3只猫和2条狗",
+			voice:  "zh-CN-XiaoxiaoNeural",
+			spoken: "我有三只猫和二条狗",
+			words:  map[string]int{"我有": 0, "三": -1, "只猫": 3, "二": -1, "条狗": 7},
+		},
+	}
+	for _, tt := range tests {
+		c := NewCommunicate(tt.input, tt.voice, WithNormalization())
+		spoken := plainText(c.ssmlText())
+		if spoken != tt.spoken {
+			t.Fatalf("spoken text = %q, want %q", spoken, tt.spoken)
+		}
+		m := newOffsetMap(spoken, plainText(tt.input))
+		for word, want := range tt.words {
+			offset := utf8.RuneCountInString(spoken[:strings.Index(spoken, word)])
+			if got := m.inputOffset(offset, utf8.RuneCountInString(word)); got != want {
+				t.Errorf("%q: inputOffset(%q) = %d, want %d", tt.input, word, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package edge_tts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ASSStyle 是 ASS 字幕的样式，颜色可以写成 "#RRGGBB" 或 ASS 的 "&HAABBGGRR"
+type ASSStyle struct {
+	Name            string
+	FontName        string
+	FontSize        int
+	PrimaryColour   string // 卡拉 OK 已唱部分的颜色
+	SecondaryColour string // 卡拉 OK 未唱部分的颜色
+	OutlineColour   string
+	BackColour      string
+	Bold            bool
+	Italic          bool
+	Outline         float64
+	Shadow          float64
+	Alignment       int // 小键盘布局，2 为底部居中
+	MarginL         int
+	MarginR         int
+	MarginV         int
+	PlayResX        int // 位置和字号对应的画面分辨率
+	PlayResY        int
+}
+
+// DefaultASSStyle 返回适合 1080p 视频的默认样式：白色文字逐词变为黄色
+func DefaultASSStyle() ASSStyle {
+	return ASSStyle{
+		Name:            "Default",
+		FontName:        "Arial",
+		FontSize:        64,
+		PrimaryColour:   "#FFFF00",
+		SecondaryColour: "#FFFFFF",
+		OutlineColour:   "#000000",
+		BackColour:      "#000000",
+		Outline:         3,
+		Shadow:          0,
+		Alignment:       2,
+		MarginL:         40,
+		MarginR:         40,
+		MarginV:         60,
+		PlayResX:        1920,
+		PlayResY:        1080,
+	}
+}
+
+// assColour 把 "#RRGGBB" 转换为 ASS 的 "&H00BBGGRR"，其他格式原样返回
+func assColour(colour string) string {
+	if len(colour) == 7 && colour[0] == '#' {
+		return "&H00" + strings.ToUpper(colour[5:7]+colour[3:5]+colour[1:3])
+	}
+	return colour
+}
+
+// assBool 返回 ASS 中的布尔值，-1 为真
+func assBool(b bool) int {
+	if b {
+		return -1
+	}
+	return 0
+}
+
+// assTextReplacer 去掉会被当作覆盖标签的花括号，并转换换行
+var assTextReplacer = strings.NewReplacer("{", "(", "}", ")", "\r\n", `\N`, "\n", `\N`)
+
+// GetASS 生成带卡拉 OK 时间的 ASS 字幕，每个词前的 \k 标签由
+// WordBoundary 的时间计算，字幕行沿用 MergeCues 的分组
+func (s *SubMaker) GetASS(style ASSStyle) string {
+	if style.Name == "" {
+		style.Name = "Default"
+	}
+
+	var b strings.Builder
+	writeASSHeader(&b, style)
+	for _, cue := range s.cues {
+		// 跳过空文本的字幕
+		if cue.Text == "" {
+			continue
+		}
+		writeASSDialogue(&b, style.Name, cue)
+	}
+	return b.String()
+}
+
+// writeASSHeader 写入 ASS 的脚本信息、样式和事件格式
+func writeASSHeader(b *strings.Builder, style ASSStyle) {
+	b.WriteString("[Script Info]\n")
+	b.WriteString("ScriptType: v4.00+\n")
+	fmt.Fprintf(b, "PlayResX: %d\n", style.PlayResX)
+	fmt.Fprintf(b, "PlayResY: %d\n", style.PlayResY)
+	b.WriteString("WrapStyle: 0\n")
+	b.WriteString("ScaledBorderAndShadow: yes\n\n")
+
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(b, "Style: %s,%s,%d,%s,%s,%s,%s,%d,%d,0,0,100,100,0,0,1,%g,%g,%d,%d,%d,%d,1\n\n",
+		style.Name, style.FontName, style.FontSize,
+		assColour(style.PrimaryColour), assColour(style.SecondaryColour),
+		assColour(style.OutlineColour), assColour(style.BackColour),
+		assBool(style.Bold), assBool(style.Italic),
+		style.Outline, style.Shadow, style.Alignment,
+		style.MarginL, style.MarginR, style.MarginV)
+
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+}
+
+// writeASSDialogue 写入一条 ASS 字幕
+func writeASSDialogue(b *strings.Builder, styleName string, cue SubCue) {
+	fmt.Fprintf(b, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s\n",
+		formatASSDuration(cue.Start), formatASSDuration(cue.End), styleName,
+		strings.ReplaceAll(cue.Speaker, ",", " "), assKaraoke(cue))
+}
+
+// assKaraoke 返回带 \k 标签的字幕文本。每个词的时长算到下一个词开始，
+// 包括词之间的停顿；时间先取整到厘秒再相减，避免误差累积
+func assKaraoke(cue SubCue) string {
+	if len(cue.Words) == 0 {
+		return assTextReplacer.Replace(cue.Text)
+	}
+
+	var b strings.Builder
+	// 第一个词之前的停顿用一个空的 \k 标签占位
+	if lead := centiseconds(cue.Words[0].Start) - centiseconds(cue.Start); lead > 0 {
+		fmt.Fprintf(&b, `{\k%d}`, lead)
+	}
+	// 按 MergeCuesWith 换行后的文本换行
+	breaks := wordLineBreaks(cue)
+	for i, word := range cue.Words {
+		end := word.End
+		if i+1 < len(cue.Words) {
+			end = cue.Words[i+1].Start
+		}
+		switch {
+		case breaks != nil && breaks[i]:
+			b.WriteString(`\N`)
+		case i > 0:
+			b.WriteString(cueSeparator(cue.Words[i-1].Text, word.Text))
+		}
+		fmt.Fprintf(&b, `{\k%d}%s`, max(centiseconds(end)-centiseconds(word.Start), 0), assTextReplacer.Replace(word.Text))
+	}
+	return b.String()
+}
+
+// centiseconds 返回四舍五入后的厘秒数
+func centiseconds(d time.Duration) int64 {
+	return int64((d + 5*time.Millisecond) / (10 * time.Millisecond))
+}
+
+// formatASSDuration 格式化 ASS 的时间，例如 "0:01:02.35"
+func formatASSDuration(d time.Duration) string {
+	cs := centiseconds(d)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
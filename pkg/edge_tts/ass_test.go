@@ -0,0 +1,69 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetASS 测试生成带卡拉 OK 标签的 ASS 字幕
+func TestGetASS(t *testing.T) {
+	sub := newTestSubMaker(t, "Fish", "{and}", "chips", "today")
+	if err := sub.MergeCues(3); err != nil {
+		t.Fatalf("MergeCues() error = %v", err)
+	}
+	sub.cues[1].Speaker = "Bob, Jr."
+
+	style := DefaultASSStyle()
+	style.FontName = "Noto Sans"
+	style.FontSize = 48
+	style.PrimaryColour = "#FF8000"
+	style.MarginV = 20
+	got := sub.GetASS(style)
+
+	for _, want := range []string{
+		"PlayResX: 1920\nPlayResY: 1080\n",
+		"Style: Default,Noto Sans,48,&H000080FF,&H00FFFFFF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,3,0,2,40,40,20,1\n",
+		`Dialogue: 0,0:00:00.00,0:00:00.68,Default,,0,0,0,,{\k24}Fish {\k24}(and) {\k20}chips` + "\n",
+		`Dialogue: 0,0:00:00.72,0:00:00.92,Default,Bob  Jr.,0,0,0,,{\k20}today` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GetASS() missing %q in\n%s", want, got)
+		}
+	}
+}
+
+// TestGetASSWrapped 测试卡拉 OK 字幕按 LineWidth 换行
+func TestGetASSWrapped(t *testing.T) {
+	sub := newTestSubMaker(t, "Fish", "and", "chips", "today")
+	if err := sub.MergeCuesWith(MergeOptions{MaxWords: 4, LineWidth: 10}); err != nil {
+		t.Fatalf("MergeCuesWith() error = %v", err)
+	}
+	want := `{\k24}Fish {\k24}and\N{\k24}chips\N{\k20}today` + "\n"
+	if got := sub.GetASS(DefaultASSStyle()); !strings.HasSuffix(got, want) {
+		t.Errorf("GetASS() = %s, want the line ending in %q", got, want)
+	}
+
+	// 在词中间换行的中文在这个词之前换行
+	sub = newTestSubMaker(t, "今天", "天气", "很好")
+	if err := sub.MergeCuesWith(MergeOptions{MaxWords: 3, LineWidth: 6}); err != nil {
+		t.Fatalf("MergeCuesWith() error = %v", err)
+	}
+	want = `{\k24}今天\N{\k24}天气{\k20}很好` + "\n"
+	if got := sub.GetASS(DefaultASSStyle()); !strings.HasSuffix(got, want) {
+		t.Errorf("GetASS() = %s, want the line ending in %q", got, want)
+	}
+
+	vtt := sub.GetVTT(WithVTTWordTimestamps())
+	if !strings.Contains(vtt, "<c>今天</c>\n<00:00:00.240><c>天气</c><00:00:00.480><c>很好</c>") {
+		t.Errorf("GetVTT() = %s, want the wrapped lines", vtt)
+	}
+}
+
+// TestFormatASSDuration 测试 ASS 时间格式
+func TestFormatASSDuration(t *testing.T) {
+	d := time.Hour + 2*time.Minute + 3*time.Second + 456*time.Millisecond
+	if got := formatASSDuration(d); got != "1:02:03.46" {
+		t.Errorf("formatASSDuration() = %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+package edge_tts
+
+import (
+	"time"
+)
+
+// mp3 bitrates in kbps for Layer III, indexed by the header bitrate index
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// mp3 sample rates in Hz, indexed by version bits and sample rate index
+var mp3SampleRates = map[byte][3]int{
+	0: {11025, 12000, 8000},  // MPEG 2.5
+	2: {22050, 24000, 16000}, // MPEG 2
+	3: {44100, 48000, 32000}, // MPEG 1
+}
+
+// mp3Frame describes one MPEG audio Layer III frame header
+type mp3Frame struct {
+	size    int // frame length in bytes, including header
+	samples int // samples per channel in this frame
+	rate    int // sample rate in Hz
+}
+
+// parseMP3Frame parses the frame header at the start of data
+func parseMP3Frame(data []byte) (mp3Frame, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+
+	version := (data[1] >> 3) & 0x03
+	layer := (data[1] >> 1) & 0x03
+	if version == 1 || layer != 1 {
+		// Reserved version, or not Layer III
+		return mp3Frame{}, false
+	}
+
+	bitrateIndex := data[2] >> 4
+	rateIndex := (data[2] >> 2) & 0x03
+	padding := int((data[2] >> 1) & 0x01)
+	if rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	frame := mp3Frame{rate: mp3SampleRates[version][rateIndex]}
+	if version == 3 {
+		bitrate := mp3BitratesV1[bitrateIndex]
+		if bitrate == 0 {
+			return mp3Frame{}, false
+		}
+		frame.samples = 1152
+		frame.size = 144*bitrate*1000/frame.rate + padding
+	} else {
+		bitrate := mp3BitratesV2[bitrateIndex]
+		if bitrate == 0 {
+			return mp3Frame{}, false
+		}
+		frame.samples = 576
+		frame.size = 72*bitrate*1000/frame.rate + padding
+	}
+	return frame, true
+}
+
+// MP3Duration returns the playing time of MP3 data, such as the audio
+// written by Save, or 0 when it has no MPEG audio frames
+func MP3Duration(data []byte) time.Duration {
+	return mp3Duration(data)
+}
+
+// mp3Duration returns the playing time of MP3 data by walking its frames
+func mp3Duration(data []byte) time.Duration {
+	var total time.Duration
+	for i := 0; i < len(data); {
+		frame, ok := parseMP3Frame(data[i:])
+		if !ok || i+frame.size > len(data) {
+			// Not at a frame boundary, resync on the next byte
+			i++
+			continue
+		}
+		total += time.Duration(frame.samples) * time.Second / time.Duration(frame.rate)
+		i += frame.size
+	}
+	return total
+}
+
+// silenceFrame is an empty frame in the service output format
+// (audio-24khz-48kbitrate-mono-mp3): MPEG 2 Layer III, 24 kHz, 48 kbps, mono.
+// Its side information is zeroed, so decoders play it back as silence.
+var silenceFrame = func() []byte {
+	frame := make([]byte, 144)
+	copy(frame, []byte{0xFF, 0xF3, 0x64, 0xC4})
+	return frame
+}()
+
+// silenceFrameDuration is the playing time of silenceFrame
+const silenceFrameDuration = 24 * time.Millisecond
+
+// silentMP3 returns MP3 data that plays silence for about d, rounded to
+// whole frames
+func silentMP3(d time.Duration) []byte {
+	if d <= 0 {
+		return nil
+	}
+	frames := int((d + silenceFrameDuration/2) / silenceFrameDuration)
+	data := make([]byte, 0, frames*len(silenceFrame))
+	for i := 0; i < frames; i++ {
+		data = append(data, silenceFrame...)
+	}
+	return data
+}
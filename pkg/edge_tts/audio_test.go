@@ -0,0 +1,55 @@
+package edge_tts
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseMP3Frame 测试解析 MP3 帧头
+func TestParseMP3Frame(t *testing.T) {
+	frame, ok := parseMP3Frame(silenceFrame)
+	if !ok {
+		t.Fatal("parseMP3Frame() failed on silence frame")
+	}
+	if frame.size != len(silenceFrame) || frame.samples != 576 || frame.rate != 24000 {
+		t.Errorf("parseMP3Frame() = %+v", frame)
+	}
+
+	// MPEG 1 Layer III, 128 kbps, 44.1 kHz, padding
+	frame, ok = parseMP3Frame([]byte{0xFF, 0xFB, 0x92, 0x64})
+	if !ok {
+		t.Fatal("parseMP3Frame() failed on MPEG 1 frame")
+	}
+	if frame.size != 418 || frame.samples != 1152 || frame.rate != 44100 {
+		t.Errorf("parseMP3Frame() = %+v", frame)
+	}
+
+	if _, ok := parseMP3Frame([]byte{0x49, 0x44, 0x33, 0x04}); ok {
+		t.Error("parseMP3Frame() should reject non-frame data")
+	}
+}
+
+// TestMP3Duration 测试计算 MP3 时长
+func TestMP3Duration(t *testing.T) {
+	tests := []struct {
+		silence time.Duration
+		want    time.Duration
+	}{
+		{0, 0},
+		{24 * time.Millisecond, 24 * time.Millisecond},
+		{500 * time.Millisecond, 504 * time.Millisecond},
+		{time.Second, 1008 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		data := silentMP3(tt.silence)
+		if got := mp3Duration(data); got != tt.want {
+			t.Errorf("mp3Duration(silentMP3(%v)) = %v, want %v", tt.silence, got, tt.want)
+		}
+	}
+
+	// 前面的垃圾数据会被跳过
+	data := append([]byte{0x00, 0x01, 0x02}, silentMP3(48*time.Millisecond)...)
+	if got := MP3Duration(data); got != 48*time.Millisecond {
+		t.Errorf("MP3Duration() with leading garbage = %v, want 48ms", got)
+	}
+}
@@ -0,0 +1,315 @@
+package edge_tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ClientProfile is the browser the client presents itself as to the
+// service. The service rejects clients whose version it no longer expects,
+// so the profile can be replaced without a new release, see
+// LoadClientConfig. Empty fields use the built-in values, and the user
+// agent and sec-ch-ua headers are derived from ChromiumFullVersion.
+type ClientProfile struct {
+	// ChromiumFullVersion is the Edge version, e.g. "143.0.3650.75"
+	ChromiumFullVersion string `json:"chromiumFullVersion,omitempty"`
+	// SecMsGecVersion is sent with the token, "1-" + ChromiumFullVersion
+	// when empty
+	SecMsGecVersion string `json:"secMsGecVersion,omitempty"`
+	UserAgent       string `json:"userAgent,omitempty"`
+	// SecCHUA and SecCHUAFullVersionList are the Sec-CH-UA and
+	// Sec-CH-UA-Full-Version-List headers
+	SecCHUA                string `json:"secChUa,omitempty"`
+	SecCHUAFullVersionList string `json:"secChUaFullVersionList,omitempty"`
+	// Origin is the Origin header of the WebSocket connection
+	Origin             string `json:"origin,omitempty"`
+	TrustedClientToken string `json:"trustedClientToken,omitempty"`
+	// Headers are added to every request, replacing the headers above
+	// with the same name
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// DefaultClientProfile returns the profile built into this version
+func DefaultClientProfile() ClientProfile {
+	return ClientProfile{
+		ChromiumFullVersion: ChromiumFullVersion,
+		Origin:              WSSHeaders["Origin"],
+		TrustedClientToken:  TrustedClientToken,
+	}
+}
+
+// withDefaults fills the empty fields of p from the built-in profile
+func (p ClientProfile) withDefaults() ClientProfile {
+	def := DefaultClientProfile()
+	if p.ChromiumFullVersion == "" {
+		p.ChromiumFullVersion = def.ChromiumFullVersion
+	}
+	if p.Origin == "" {
+		p.Origin = def.Origin
+	}
+	if p.TrustedClientToken == "" {
+		p.TrustedClientToken = def.TrustedClientToken
+	}
+	return p
+}
+
+// MajorVersion returns the major Chromium version, e.g. "143"
+func (p ClientProfile) MajorVersion() string {
+	major, _, _ := strings.Cut(p.withDefaults().ChromiumFullVersion, ".")
+	return major
+}
+
+// GecVersion returns the Sec-MS-GEC-Version sent with the token
+func (p ClientProfile) GecVersion() string {
+	if p.SecMsGecVersion != "" {
+		return p.SecMsGecVersion
+	}
+	return "1-" + p.withDefaults().ChromiumFullVersion
+}
+
+// WSSURL returns the synthesis endpoint with the trusted client token
+func (p ClientProfile) WSSURL() string {
+	return "wss://" + BaseURL + "/edge/v1?TrustedClientToken=" + p.withDefaults().TrustedClientToken
+}
+
+// VoiceListURL returns the voice list endpoint with the trusted client token
+func (p ClientProfile) VoiceListURL() string {
+	return "https://" + BaseURL + "/voices/list?trustedclienttoken=" + p.withDefaults().TrustedClientToken
+}
+
+// Header returns the browser headers sent with every request, the same as
+// BaseHeaders for the default profile
+func (p ClientProfile) Header() http.Header {
+	p = p.withDefaults()
+	major, full := p.MajorVersion(), p.ChromiumFullVersion
+
+	userAgent := p.UserAgent
+	if userAgent == "" {
+		userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36" +
+			" (KHTML, like Gecko) Chrome/" + major + ".0.0.0 Safari/537.36" +
+			" Edg/" + major + ".0.0.0"
+	}
+	secCHUA := p.SecCHUA
+	if secCHUA == "" {
+		secCHUA = `" Not;A Brand";v="99", "Microsoft Edge";v="` + major + `",` +
+			` "Chromium";v="` + major + `"`
+	}
+	fullVersionList := p.SecCHUAFullVersionList
+	if fullVersionList == "" {
+		fullVersionList = `" Not;A Brand";v="99.0.0.0", "Microsoft Edge";v="` + full + `",` +
+			` "Chromium";v="` + full + `"`
+	}
+
+	header := http.Header{}
+	for k, v := range BaseHeaders {
+		header.Set(k, v)
+	}
+	header.Set("User-Agent", userAgent)
+	header.Set("Sec-CH-UA", secCHUA)
+	header.Set("Sec-CH-UA-Full-Version", full)
+	header.Set("Sec-CH-UA-Full-Version-List", fullVersionList)
+	for k, v := range p.Headers {
+		header.Set(k, v)
+	}
+	return header
+}
+
+// WebSocketHeader returns the headers of the synthesis connection, the same
+// as WSSHeaders for the default profile
+func (p ClientProfile) WebSocketHeader() http.Header {
+	header := http.Header{}
+	for k, v := range WSSHeaders {
+		header.Set(k, v)
+	}
+	for k, v := range p.Header() {
+		header[k] = v
+	}
+	header.Set("Origin", p.withDefaults().Origin)
+	for k, v := range p.Headers {
+		header.Set(k, v)
+	}
+	return header
+}
+
+// TokenProvider generates the Sec-MS-GEC token sent with every request
+type TokenProvider interface {
+	Token(ctx context.Context, profile ClientProfile) (string, error)
+}
+
+// TokenProviderFunc adapts a function to a TokenProvider
+type TokenProviderFunc func(ctx context.Context, profile ClientProfile) (string, error)
+
+// Token calls f
+func (f TokenProviderFunc) Token(ctx context.Context, profile ClientProfile) (string, error) {
+	return f(ctx, profile)
+}
+
+// SecMsGecTokenProvider is the built-in TokenProvider: a SHA-256 of the
+// time, rounded down to five minutes, and the trusted client token
+type SecMsGecTokenProvider struct {
+	// Clock tells the time, the system time when nil. Communicate and the
+	// voice list set it to their own clock, corrected by the server clock.
+	Clock Clock
+}
+
+// Token returns the token for the current time
+func (p SecMsGecTokenProvider) Token(ctx context.Context, profile ClientProfile) (string, error) {
+	now := time.Now()
+	if p.Clock != nil {
+		now = p.Clock.Now()
+	}
+	return secMsGec(now, profile.withDefaults().TrustedClientToken), nil
+}
+
+// CommandTokenProvider runs a command and uses what it prints as the
+// token, so a new token algorithm can be used without a new release. The
+// command gets EDGE_TTS_TRUSTED_CLIENT_TOKEN and EDGE_TTS_CHROMIUM_VERSION
+// in its environment.
+type CommandTokenProvider struct {
+	// Command is the program and its arguments
+	Command []string
+}
+
+// Token runs the command
+func (p CommandTokenProvider) Token(ctx context.Context, profile ClientProfile) (string, error) {
+	if len(p.Command) == 0 {
+		return "", errors.New("empty token command")
+	}
+	profile = profile.withDefaults()
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Env = append(os.Environ(),
+		"EDGE_TTS_TRUSTED_CLIENT_TOKEN="+profile.TrustedClientToken,
+		"EDGE_TTS_CHROMIUM_VERSION="+profile.ChromiumFullVersion,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", errors.New("token command printed nothing")
+	}
+	return token, nil
+}
+
+// WithClientProfile sets the browser profile, instead of the one from
+// LoadClientConfig
+func WithClientProfile(profile ClientProfile) Option {
+	return func(c *TTSConfig) {
+		c.ClientProfile = &profile
+	}
+}
+
+// WithTokenProvider sets how the Sec-MS-GEC token is generated, instead of
+// the provider from LoadClientConfig
+func WithTokenProvider(provider TokenProvider) Option {
+	return func(c *TTSConfig) {
+		c.TokenProvider = provider
+	}
+}
+
+// clientConfigFile is the content of the client profile file
+type clientConfigFile struct {
+	ClientProfile
+	// TokenCommand is run by a CommandTokenProvider
+	TokenCommand []string `json:"tokenCommand,omitempty"`
+}
+
+// DefaultClientConfigPath returns the client profile file in the user
+// config directory, e.g. ~/.config/edge-tts/client.json on Linux
+func DefaultClientConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "edge-tts", "client.json"), nil
+}
+
+// LoadClientConfig returns the profile and token provider used when no
+// option sets them. It starts from the built-in values, applies the JSON
+// file named by EDGE_TTS_CLIENT_CONFIG, or DefaultClientConfigPath if it
+// exists, and then these environment variables:
+//
+//	EDGE_TTS_CHROMIUM_VERSION      ChromiumFullVersion
+//	EDGE_TTS_SEC_MS_GEC_VERSION    SecMsGecVersion
+//	EDGE_TTS_USER_AGENT            UserAgent
+//	EDGE_TTS_ORIGIN                Origin
+//	EDGE_TTS_TRUSTED_CLIENT_TOKEN  TrustedClientToken
+//	EDGE_TTS_TOKEN_COMMAND         a CommandTokenProvider, split on spaces
+func LoadClientConfig() (ClientProfile, TokenProvider, error) {
+	var file clientConfigFile
+	path := os.Getenv("EDGE_TTS_CLIENT_CONFIG")
+	if path == "" {
+		path, _ = DefaultClientConfigPath()
+		if _, err := os.Stat(path); err != nil {
+			path = ""
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ClientProfile{}, nil, err
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return ClientProfile{}, nil, fmt.Errorf("parse client config %s failed: %w", path, err)
+		}
+	}
+
+	profile := file.ClientProfile
+	for env, field := range map[string]*string{
+		"EDGE_TTS_CHROMIUM_VERSION":     &profile.ChromiumFullVersion,
+		"EDGE_TTS_SEC_MS_GEC_VERSION":   &profile.SecMsGecVersion,
+		"EDGE_TTS_USER_AGENT":           &profile.UserAgent,
+		"EDGE_TTS_ORIGIN":               &profile.Origin,
+		"EDGE_TTS_TRUSTED_CLIENT_TOKEN": &profile.TrustedClientToken,
+	} {
+		if value := os.Getenv(env); value != "" {
+			*field = value
+		}
+	}
+
+	command := file.TokenCommand
+	if env := os.Getenv("EDGE_TTS_TOKEN_COMMAND"); env != "" {
+		command = strings.Fields(env)
+	}
+	var provider TokenProvider = SecMsGecTokenProvider{}
+	if len(command) > 0 {
+		provider = CommandTokenProvider{Command: command}
+	}
+	return profile.withDefaults(), provider, nil
+}
+
+// clientConfig returns the profile and token provider of the options,
+// falling back to LoadClientConfig for those that are not set
+func (c *TTSConfig) clientConfig() (ClientProfile, TokenProvider, error) {
+	var profile ClientProfile
+	var provider TokenProvider
+	if c.ClientProfile == nil || c.TokenProvider == nil {
+		var err error
+		if profile, provider, err = LoadClientConfig(); err != nil {
+			return ClientProfile{}, nil, err
+		}
+	}
+	if c.ClientProfile != nil {
+		profile = c.ClientProfile.withDefaults()
+	}
+	if c.TokenProvider != nil {
+		provider = c.TokenProvider
+	}
+	// The built-in token follows the clock set by WithClock
+	if p, ok := provider.(SecMsGecTokenProvider); ok && p.Clock == nil && c.Clock != nil {
+		provider = SecMsGecTokenProvider{Clock: c.Clock}
+	}
+	return profile, provider, nil
+}
@@ -0,0 +1,152 @@
+package edge_tts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// headerOf 把 map 形式的 headers 转为 http.Header
+func headerOf(m map[string]string) http.Header {
+	h := http.Header{}
+	for k, v := range m {
+		h.Set(k, v)
+	}
+	return h
+}
+
+// TestDefaultClientProfile 测试默认配置与内置常量一致
+func TestDefaultClientProfile(t *testing.T) {
+	p := DefaultClientProfile()
+	if got := p.Header(); !reflect.DeepEqual(got, headerOf(BaseHeaders)) {
+		t.Errorf("Header() = %v, want BaseHeaders", got)
+	}
+	if got := p.WebSocketHeader(); !reflect.DeepEqual(got, headerOf(WSSHeaders)) {
+		t.Errorf("WebSocketHeader() = %v, want WSSHeaders", got)
+	}
+	if p.WSSURL() != WSSURL || p.VoiceListURL() != VoiceList {
+		t.Errorf("URLs = %s, %s, want WSSURL and VoiceList", p.WSSURL(), p.VoiceListURL())
+	}
+	if p.GecVersion() != SEC_MS_GEC_VERSION || p.MajorVersion() != ChromiumMajorVersion {
+		t.Errorf("versions = %s, %s", p.GecVersion(), p.MajorVersion())
+	}
+	clock := ClockFunc(func() time.Time { return time.Date(2024, 12, 4, 10, 30, 45, 0, time.UTC) })
+	token, err := SecMsGecTokenProvider{Clock: clock}.Token(context.Background(), p)
+	if err != nil || token != secMsGec(clock.Now(), TrustedClientToken) {
+		t.Errorf("Token() = %s, %v, want secMsGec() of the clock", token, err)
+	}
+}
+
+// TestClientProfileVersion 测试由版本号生成的 headers 和自定义 headers
+func TestClientProfileVersion(t *testing.T) {
+	p := ClientProfile{
+		ChromiumFullVersion: "150.0.1.2",
+		TrustedClientToken:  "TOKEN",
+		Headers:             map[string]string{"Accept-Language": "de-DE"},
+	}
+	h := p.Header()
+	if ua := h.Get("User-Agent"); !strings.Contains(ua, "Chrome/150.0.0.0") || !strings.Contains(ua, "Edg/150.0.0.0") {
+		t.Errorf("User-Agent = %q, want version 150", ua)
+	}
+	if got := h.Get("Sec-CH-UA-Full-Version-List"); !strings.Contains(got, `"Microsoft Edge";v="150.0.1.2"`) {
+		t.Errorf("Sec-CH-UA-Full-Version-List = %q", got)
+	}
+	if h.Get("Accept-Language") != "de-DE" || p.WebSocketHeader().Get("Accept-Language") != "de-DE" {
+		t.Error("Headers do not replace Accept-Language")
+	}
+	if got := p.WebSocketHeader().Get("Origin"); got != WSSHeaders["Origin"] {
+		t.Errorf("Origin = %q, want the default", got)
+	}
+	if p.GecVersion() != "1-150.0.1.2" || !strings.HasSuffix(p.WSSURL(), "TrustedClientToken=TOKEN") {
+		t.Errorf("GecVersion() = %s, WSSURL() = %s", p.GecVersion(), p.WSSURL())
+	}
+}
+
+// TestLoadClientConfig 测试从配置文件和环境变量加载客户端配置
+func TestLoadClientConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.json")
+	config := `{"chromiumFullVersion": "150.0.1.2", "origin": "https://example.com", "tokenCommand": ["echo", "ABC"]}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDGE_TTS_CLIENT_CONFIG", path)
+	t.Setenv("EDGE_TTS_TRUSTED_CLIENT_TOKEN", "FROM-ENV")
+	t.Setenv("EDGE_TTS_TOKEN_COMMAND", "")
+
+	profile, provider, err := LoadClientConfig()
+	if err != nil {
+		t.Fatalf("LoadClientConfig() error = %v", err)
+	}
+	want := ClientProfile{ChromiumFullVersion: "150.0.1.2", Origin: "https://example.com", TrustedClientToken: "FROM-ENV"}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("profile = %+v, want %+v", profile, want)
+	}
+	if !reflect.DeepEqual(provider, CommandTokenProvider{Command: []string{"echo", "ABC"}}) {
+		t.Errorf("provider = %#v, want the token command", provider)
+	}
+	if _, err := exec.LookPath("echo"); err == nil {
+		if token, err := provider.Token(context.Background(), profile); err != nil || token != "ABC" {
+			t.Errorf("Token() = %q, %v, want ABC", token, err)
+		}
+	}
+
+	// 选项优先于配置文件
+	c := NewCommunicate("hi", "en-US-AvaNeural", WithClientProfile(ClientProfile{TrustedClientToken: "OPT"}))
+	if c.profile.ChromiumFullVersion != ChromiumFullVersion || !strings.HasSuffix(c.wsURL, "=OPT") {
+		t.Errorf("option profile = %+v, wsURL %s", c.profile, c.wsURL)
+	}
+	if _, ok := c.tokens.(CommandTokenProvider); !ok {
+		t.Errorf("tokens = %#v, want the configured token command", c.tokens)
+	}
+
+	os.WriteFile(path, []byte("{"), 0644)
+	if _, _, err := LoadClientConfig(); err == nil {
+		t.Error("LoadClientConfig() with invalid file want error")
+	}
+	ch, err := NewCommunicate("hi", "en-US-AvaNeural").Stream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk := <-ch; chunk.Type != "error" {
+		t.Errorf("Stream() with invalid client config = %+v, want an error chunk", chunk)
+	}
+}
+
+// TestVoiceCacheClientProfile 测试下载声音列表时使用客户端配置和 token
+func TestVoiceCacheClientProfile(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cache := &VoiceCache{
+		Path:    filepath.Join(t.TempDir(), "voices.json"),
+		TTL:     time.Hour,
+		Profile: &ClientProfile{ChromiumFullVersion: "150.0.1.2", UserAgent: "test-agent"},
+		TokenProvider: TokenProviderFunc(func(ctx context.Context, p ClientProfile) (string, error) {
+			return "TOKEN-" + p.MajorVersion(), nil
+		}),
+		url: server.URL + "/voices/list?trustedclienttoken=test",
+	}
+	if _, _, err := cache.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if q := got.URL.Query(); q.Get("Sec-MS-GEC") != "TOKEN-150" || q.Get("Sec-MS-GEC-Version") != "1-150.0.1.2" {
+		t.Errorf("query = %v, want the provider token and profile version", q)
+	}
+	if ua := got.Header.Get("User-Agent"); ua != "test-agent" {
+		t.Errorf("User-Agent = %q, want test-agent", ua)
+	}
+}
@@ -0,0 +1,85 @@
+package edge_tts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock tells the time used for the Sec-MS-GEC token and the X-Timestamp
+// of every message. Tests set a fixed clock with WithClock to get the same
+// requests every time.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now calls f
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// serverClock is the default Clock: the system time corrected by the clock
+// skew the service reported, see adjustClockSkew. Each client has its own.
+type serverClock struct {
+	mu   sync.RWMutex
+	skew time.Duration
+}
+
+// Now returns the corrected time
+func (c *serverClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return time.Now().Add(c.skew)
+}
+
+// adjustClockSkew sets the skew from date, the RFC 2616 Date header of a
+// response
+func (c *serverClock) adjustClockSkew(date string) error {
+	serverTime, err := parseRFC2616Date(date)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skew = time.Duration(serverTime-time.Now().UTC().Unix()) * time.Second
+	return nil
+}
+
+// IDGenerator makes the identifiers sent to the service. Tests set fixed
+// identifiers with WithIDGenerator to compare the exact messages.
+type IDGenerator interface {
+	// ConnectionID identifies a WebSocket connection
+	ConnectionID() string
+	// RequestID identifies the SSML request of a connection
+	RequestID() string
+	// MUID is the anonymous user id sent as the muid cookie
+	MUID() string
+}
+
+// randomIDs is the default IDGenerator: random UUIDs and MUIDs
+type randomIDs struct{}
+
+func (randomIDs) ConnectionID() string { return uuid.New().String() }
+func (randomIDs) RequestID() string    { return uuid.New().String() }
+func (randomIDs) MUID() string         { return generateMUID() }
+
+// WithClock sets the clock used for tokens and message timestamps, by
+// default the system time corrected by the server clock
+func WithClock(clock Clock) Option {
+	return func(c *TTSConfig) {
+		c.Clock = clock
+	}
+}
+
+// WithIDGenerator sets how connection ids, request ids and MUIDs are made,
+// random by default
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(c *TTSConfig) {
+		c.IDs = ids
+	}
+}
@@ -7,26 +7,39 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"html"
 	"net"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 // Communicate is the main structure for communicating with Edge TTS service
 type Communicate struct {
-	config *TTSConfig
-	client *http.Client
-	proxy  string
-	wsURL  string
-	state  *CommunicateState
+	config  *TTSConfig
+	client  *http.Client
+	proxy   string
+	wsURL   string
+	profile ClientProfile
+	tokens  TokenProvider
+	clock   Clock
+	ids     IDGenerator
+	muid    string
+	// configErr is why the options are invalid, it is returned by Stream
+	configErr error
+	// clientErr is why the client profile or the MUID could not be
+	// loaded, it is reported by Stream
+	clientErr error
+	state     *CommunicateState
+	fit       *FitResult
 }
 
-// NewCommunicate creates a new Communicate instance
+// NewCommunicate creates a new Communicate instance. Invalid options are
+// reported by Stream and Save, see TTSConfig.Validate.
 func NewCommunicate(text, voice string, opts ...Option) *Communicate {
 	// Get system proxy
 	proxy := os.Getenv("HTTP_PROXY")
@@ -42,15 +55,39 @@ func NewCommunicate(text, voice string, opts ...Option) *Communicate {
 	}
 
 	// Validate configuration
-	if err := config.Validate(); err != nil {
-		panic(err)
+	configErr := config.Validate()
+
+	profile, tokens, clientErr := config.clientConfig()
+	var clock Clock = &serverClock{}
+	if config.Clock != nil {
+		clock = config.Clock
+	}
+	// The built-in token follows the clock of this client
+	if p, ok := tokens.(SecMsGecTokenProvider); ok && p.Clock == nil {
+		tokens = SecMsGecTokenProvider{Clock: clock}
+	}
+	var ids IDGenerator = randomIDs{}
+	if config.IDs != nil {
+		ids = config.IDs
+	}
+	// The MUID stays the same for every connection of this client
+	muid, err := config.muid(ids)
+	if err != nil && clientErr == nil {
+		clientErr = fmt.Errorf("load MUID failed: %w", err)
 	}
 
 	return &Communicate{
-		config: config,
-		client: &http.Client{},
-		proxy:  proxy,
-		wsURL:  WSSURL,
+		config:    config,
+		client:    &http.Client{},
+		proxy:     proxy,
+		wsURL:     profile.WSSURL(),
+		profile:   profile,
+		tokens:    tokens,
+		clock:     clock,
+		ids:       ids,
+		muid:      muid,
+		configErr: configErr,
+		clientErr: clientErr,
 		state: &CommunicateState{
 			PartialText: []byte(text),
 		},
@@ -81,8 +118,113 @@ func WithPitch(pitch string) Option {
 	}
 }
 
-// Stream method implementation
+// WithStyle sets the speaking style, e.g. "cheerful"
+func WithStyle(style string) Option {
+	return func(c *TTSConfig) {
+		c.Style = style
+	}
+}
+
+// WithLocale sets the xml:lang of the SSML document, overriding the
+// locale derived from the voice name (for example Voice.Locale)
+func WithLocale(locale string) Option {
+	return func(c *TTSConfig) {
+		c.Locale = locale
+	}
+}
+
+// WithLangSpans marks parts of the text as spoken in another language,
+// each span is wrapped in a <lang xml:lang> element
+func WithLangSpans(spans ...LangSpan) Option {
+	return func(c *TTSConfig) {
+		c.LangSpans = append(c.LangSpans, spans...)
+	}
+}
+
+// WithNormalization reads numbers, dates, units and abbreviations of the
+// text the way the voice locale speaks them, followed by any extra
+// normalizers
+func WithNormalization(extra ...TextNormalizer) Option {
+	return func(c *TTSConfig) {
+		c.Normalize = true
+		c.Normalizers = append(c.Normalizers, extra...)
+	}
+}
+
+// WithNormalizer adds text normalizers without the built-in locale rules
+func WithNormalizer(normalizers ...TextNormalizer) Option {
+	return func(c *TTSConfig) {
+		c.Normalizers = append(c.Normalizers, normalizers...)
+	}
+}
+
+// WithLexicon adds custom pronunciation lexicons
+func WithLexicon(lexicons ...*Lexicon) Option {
+	return func(c *TTSConfig) {
+		c.Lexicons = append(c.Lexicons, lexicons...)
+	}
+}
+
+// WithSentenceBoundaries makes Stream also emit SentenceBoundary chunks
+func WithSentenceBoundaries() Option {
+	return func(c *TTSConfig) {
+		c.SentenceBoundaries = true
+	}
+}
+
+// WithSubtitleMerge makes Save group words into subtitles, see MergeCuesWith
+func WithSubtitleMerge(opts MergeOptions) Option {
+	return func(c *TTSConfig) {
+		c.SubtitleMerge = &opts
+	}
+}
+
+// WithSubtitleReadability makes Save extend short subtitles, see ApplyReadability
+func WithSubtitleReadability(opts ReadabilityOptions) Option {
+	return func(c *TTSConfig) {
+		c.SubtitleReadability = &opts
+	}
+}
+
+// WithAlignedSubtitles makes Save align subtitles back to the input text,
+// so they keep its punctuation and casing
+func WithAlignedSubtitles() Option {
+	return func(c *TTSConfig) {
+		c.AlignSubtitles = true
+	}
+}
+
+// WithTargetDuration makes Stream adjust the rate until the audio lasts d,
+// within the tolerance set by WithFitTolerance, see FitResult
+func WithTargetDuration(d time.Duration) Option {
+	return func(c *TTSConfig) {
+		c.TargetDuration = d
+	}
+}
+
+// WithFitTolerance sets how far the audio may be from the target duration,
+// DefaultFitTolerance by default
+func WithFitTolerance(tolerance time.Duration) Option {
+	return func(c *TTSConfig) {
+		c.FitTolerance = tolerance
+	}
+}
+
+// Stream method implementation. With WithTargetDuration the text is
+// synthesized until it fits and only the final attempt is sent, so nothing
+// arrives before the fitting is done.
 func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	if c.config.TargetDuration > 0 {
+		return c.fitStream(ctx)
+	}
+	return c.stream(ctx)
+}
+
+// stream synthesizes the text once with the current configuration
+func (c *Communicate) stream(ctx context.Context) (<-chan TTSChunk, error) {
 	ch := make(chan TTSChunk, 100)
 
 	go func() {
@@ -111,33 +253,20 @@ func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
 			EnableCompression: true,
 		}
 
-		// Generate connection ID and security token
-		connID := uuid.New().String()
-		secMsGec := generateSecMsGec()
-
-		// Build complete WebSocket URL (参数顺序与 Python 一致)
-		wsURL := fmt.Sprintf("%s&ConnectionId=%s&Sec-MS-GEC=%s&Sec-MS-GEC-Version=%s",
-			c.wsURL, connID, secMsGec, SEC_MS_GEC_VERSION)
-
-		// Prepare request headers
-		headers := http.Header{}
-		for k, v := range WSSHeaders {
-			headers.Set(k, v)
+		if c.clientErr != nil {
+			ch <- TTSChunk{Type: "error", Data: []byte(c.clientErr.Error())}
+			return
 		}
 
-		// 添加 MUID Cookie (关键修复!)
-		headers = headersWithMUID(headers)
-
-		// Establish WebSocket connection
-		conn, _, err := dialer.Dial(wsURL, headers)
+		conn, err := c.dial(ctx, &dialer)
 		if err != nil {
 			ch <- TTSChunk{Type: "error", Data: []byte(err.Error())}
 			return
 		}
 
 		// Send command request (使用 JavaScript 风格的时间戳)
-		cmdReq := fmt.Sprintf("X-Timestamp:%s\r\nContent-Type:application/json; charset=utf-8\r\nPath:speech.config\r\n\r\n{\"context\":{\"synthesis\":{\"audio\":{\"metadataoptions\":{\"sentenceBoundaryEnabled\":\"false\",\"wordBoundaryEnabled\":\"true\"},\"outputFormat\":\"audio-24khz-48kbitrate-mono-mp3\"}}}}\r\n",
-			dateToString())
+		cmdReq := fmt.Sprintf("X-Timestamp:%s\r\nContent-Type:application/json; charset=utf-8\r\nPath:speech.config\r\n\r\n{\"context\":{\"synthesis\":{\"audio\":{\"metadataoptions\":{\"sentenceBoundaryEnabled\":\"%t\",\"wordBoundaryEnabled\":\"true\"},\"outputFormat\":\"audio-24khz-48kbitrate-mono-mp3\"}}}}\r\n",
+			dateToString(c.clock.Now()), c.config.SentenceBoundaries)
 
 		if err := conn.WriteMessage(websocket.TextMessage, []byte(cmdReq)); err != nil {
 			ch <- TTSChunk{Type: "error", Data: []byte(err.Error())}
@@ -145,10 +274,14 @@ func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
 		}
 
 		// Send SSML request (时间戳格式需要加 Z 后缀)
+		ssmlOpen, ssmlClose := c.ssmlEnvelope()
+		text := c.ssmlText()
+		// Reported offsets are in the spoken text, the subtitles align to the input
+		offsets := newOffsetMap(plainText(text), plainText(c.config.Text))
 		ssmlReq := fmt.Sprintf("X-RequestId:%s\r\nContent-Type:application/ssml+xml\r\nX-Timestamp:%sZ\r\nPath:ssml\r\n\r\n%s",
-			uuid.New().String(),
-			dateToString(),
-			c.createSSML())
+			c.ids.RequestID(),
+			dateToString(c.clock.Now()),
+			ssmlOpen+text+ssmlClose)
 
 		if err := conn.WriteMessage(websocket.TextMessage, []byte(ssmlReq)); err != nil {
 			ch <- TTSChunk{Type: "error", Data: []byte(err.Error())}
@@ -268,10 +401,12 @@ func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
 							Metadata []struct {
 								Type string `json:"Type"`
 								Data struct {
-									Offset   int64 `json:"Offset"`
-									Duration int64 `json:"Duration"`
-									Text     struct {
-										Text string `json:"Text"`
+									Offset     int64  `json:"Offset"`
+									Duration   int64  `json:"Duration"`
+									TextOffset *int64 `json:"TextOffset"`
+									Text       struct {
+										Text   string `json:"Text"`
+										Length int    `json:"Length"`
 									} `json:"Text"`
 								} `json:"Data"`
 							} `json:"Metadata"`
@@ -284,16 +419,24 @@ func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
 
 						// Process each metadata item
 						for _, meta := range metadata.Metadata {
-							if meta.Type == "WordBoundary" {
+							if meta.Type == "WordBoundary" || meta.Type == "SentenceBoundary" {
 								// 确保文本内容不为空
 								if meta.Data.Text.Text == "" {
 									continue
 								}
+								textOffset := -1
+								if meta.Data.TextOffset != nil {
+									if offset := spokenTextOffset(ssmlOpen, text, int(*meta.Data.TextOffset)); offset >= 0 {
+										textOffset = offsets.inputOffset(offset, meta.Data.Text.Length)
+									}
+								}
 								ch <- TTSChunk{
-									Type:     "WordBoundary",
-									Offset:   float64(meta.Data.Offset),
-									Duration: float64(meta.Data.Duration),
-									Text:     meta.Data.Text.Text,
+									Type:       meta.Type,
+									Offset:     float64(meta.Data.Offset),
+									Duration:   float64(meta.Data.Duration),
+									Text:       html.UnescapeString(meta.Data.Text.Text),
+									TextOffset: textOffset,
+									TextLength: meta.Data.Text.Length,
 								}
 							}
 						}
@@ -308,6 +451,17 @@ func (c *Communicate) Stream(ctx context.Context) (<-chan TTSChunk, error) {
 
 // Save method implementation
 func (c *Communicate) Save(ctx context.Context, audioPath string, subtitlePath string) error {
+	return c.SaveWithTimings(ctx, audioPath, subtitlePath, "")
+}
+
+// SaveWithTimings is Save that also writes the word and sentence boundary
+// timings to timingsPath if it is not empty. .jsonl and .ndjson files get
+// one JSON line per boundary as it arrives, other files a JSON array when
+// the stream ends.
+func (c *Communicate) SaveWithTimings(ctx context.Context, audioPath, subtitlePath, timingsPath string) error {
+	if timingsPath != "" {
+		c.config.SentenceBoundaries = true
+	}
 	ch, err := c.Stream(ctx)
 	if err != nil {
 		return err
@@ -330,10 +484,41 @@ func (c *Communicate) Save(ctx context.Context, audioPath string, subtitlePath s
 		defer subtitleFile.Close()
 	}
 
+	// Create timings file (if specified)
+	var timingsFile *os.File
+	if timingsPath != "" {
+		timingsFile, err = os.Create(timingsPath)
+		if err != nil {
+			return err
+		}
+		defer timingsFile.Close()
+	}
+
 	// Create subtitle generator
 	submaker := NewSubMaker()
 
+	// JSON Lines 的时间边收边写，JSON 数组在最后写入
+	var timingWriter *TimingWriter
+	if timingsFile != nil && isJSONLines(timingsPath) {
+		timingWriter = NewTimingWriter(timingsFile, c.config.Text)
+	}
+
+	// 不需要整段文本的字幕边收边写，LRC 的长度和对齐需要全部的边界
+	var subWriter *SubtitleWriter
+	format := SubtitleFormatForPath(subtitlePath)
+	if subtitleFile != nil && format != SubtitleLRC && !c.config.AlignSubtitles {
+		subWriter, err = NewSubtitleWriter(subtitleFile, format, SubtitleWriterOptions{
+			Merge:       c.config.SubtitleMerge,
+			Readability: c.config.SubtitleReadability,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	audioReceived := false
+	// 保留音频用于计算字幕合成信息中的长度，音频块不一定在帧边界上
+	var audio []byte
 
 	for chunk := range ch {
 		if chunk.Type == "error" {
@@ -341,13 +526,28 @@ func (c *Communicate) Save(ctx context.Context, audioPath string, subtitlePath s
 		}
 		if chunk.Type == "audio" {
 			audioReceived = true
+			if subtitleFile != nil && subWriter == nil {
+				audio = append(audio, chunk.Data...)
+			}
 			// Write audio data to buffer
 			if _, err := audioFile.Write(chunk.Data); err != nil {
 				return err
 			}
-		} else if chunk.Type == "WordBoundary" && subtitleFile != nil {
-			if err := submaker.Feed(chunk); err != nil {
-				return fmt.Errorf("error feeding chunk: %v", err)
+		} else if chunk.Type == "WordBoundary" || chunk.Type == "SentenceBoundary" {
+			if subWriter != nil {
+				if err := subWriter.Feed(chunk); err != nil {
+					return fmt.Errorf("error writing subtitles: %v", err)
+				}
+			}
+			if timingWriter != nil {
+				if err := timingWriter.Feed(chunk); err != nil {
+					return fmt.Errorf("error writing timings: %v", err)
+				}
+			}
+			if (subtitleFile != nil && subWriter == nil) || (timingsFile != nil && timingWriter == nil) {
+				if err := submaker.Feed(chunk); err != nil {
+					return fmt.Errorf("error feeding chunk: %v", err)
+				}
 			}
 		}
 	}
@@ -358,8 +558,33 @@ func (c *Communicate) Save(ctx context.Context, audioPath string, subtitlePath s
 	}
 
 	// Generate subtitle file
-	if subtitleFile != nil {
-		if _, err := subtitleFile.WriteString(submaker.GetSRT()); err != nil {
+	if subWriter != nil {
+		if err := subWriter.Flush(); err != nil {
+			return err
+		}
+	} else if subtitleFile != nil {
+		if c.config.AlignSubtitles {
+			submaker.AlignText(c.config.Text)
+		}
+		if c.config.SubtitleMerge != nil {
+			if err := submaker.MergeCuesWith(*c.config.SubtitleMerge); err != nil {
+				return err
+			}
+		}
+		if c.config.SubtitleReadability != nil {
+			if err := submaker.ApplyReadability(*c.config.SubtitleReadability); err != nil {
+				return err
+			}
+		}
+		submaker.lrcTags = LRCTags{Title: lrcTitle(c.config.Text), Artist: c.config.Voice, Length: mp3Duration(audio)}
+		if _, err := subtitleFile.WriteString(submaker.GetSubtitles(subtitlePath)); err != nil {
+			return err
+		}
+	}
+
+	// Generate timings file
+	if timingsFile != nil && timingWriter == nil {
+		if err := submaker.writeTimings(timingsFile, timingsPath, c.config.Text); err != nil {
 			return err
 		}
 	}
@@ -367,22 +592,144 @@ func (c *Communicate) Save(ctx context.Context, audioPath string, subtitlePath s
 	return nil
 }
 
+// dial opens the WebSocket connection. The built-in clock is corrected by
+// the date of a 403 response, and the connection is retried once.
+func (c *Communicate) dial(ctx context.Context, dialer *websocket.Dialer) (*websocket.Conn, error) {
+	// Only one retry after the clock skew was adjusted
+	for retried := false; ; retried = true {
+		// Generate connection ID and security token
+		connID := c.ids.ConnectionID()
+		secMsGec, err := c.tokens.Token(ctx, c.profile)
+		if err != nil {
+			return nil, err
+		}
+
+		// Build complete WebSocket URL (参数顺序与 Python 一致)
+		wsURL := fmt.Sprintf("%s&ConnectionId=%s&Sec-MS-GEC=%s&Sec-MS-GEC-Version=%s",
+			c.wsURL, connID, secMsGec, c.profile.GecVersion())
+
+		// Prepare request headers
+		headers := c.profile.WebSocketHeader()
+
+		// 添加 MUID Cookie (关键修复!)，有 cookie jar 时由 jar 发送
+		if jar := c.config.CookieJar; jar != nil {
+			if err := addMUIDCookie(jar, wsURL, c.muid); err != nil {
+				return nil, err
+			}
+			dialer.Jar = jar
+		} else {
+			headers = headersWithMUID(headers, c.muid)
+		}
+
+		// Establish WebSocket connection
+		conn, resp, err := dialer.Dial(wsURL, headers)
+		if err == nil {
+			return conn, nil
+		}
+		// If 403 error, may need to adjust clock skew
+		clock, ok := c.clock.(*serverClock)
+		if retried || !ok || resp == nil || resp.StatusCode != http.StatusForbidden {
+			return nil, err
+		}
+		if err := handleClientResponseError(resp, clock); err != nil {
+			return nil, err
+		}
+	}
+}
+
 // createSSML creates SSML string
 func (c *Communicate) createSSML() string {
-	return fmt.Sprintf(
-		"<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-US'>"+
-			"<voice name='%s'>"+
-			"<prosody pitch='%s' rate='%s' volume='%s'>"+
-			"%s"+
-			"</prosody>"+
-			"</voice>"+
-			"</speak>",
-		c.config.Voice,
+	before, after := c.ssmlEnvelope()
+	return before + c.ssmlText() + after
+}
+
+// ssmlEnvelope returns the SSML elements written before and after the text
+func (c *Communicate) ssmlEnvelope() (string, string) {
+	prosody := fmt.Sprintf("<prosody pitch='%s' rate='%s' volume='%s'>",
 		c.config.Pitch,
 		c.config.Rate,
 		c.config.Volume,
-		c.config.Text,
 	)
+	before, after := prosody, "</prosody>"
+
+	// Speaking styles live in the mstts namespace
+	namespaces := "xmlns='http://www.w3.org/2001/10/synthesis'"
+	if c.config.Style != "" {
+		namespaces += " xmlns:mstts='https://www.w3.org/2001/mstts'"
+		before = fmt.Sprintf("<mstts:express-as style='%s'>", c.config.Style) + before
+		after += "</mstts:express-as>"
+	}
+
+	before = fmt.Sprintf("<speak version='1.0' %s xml:lang='%s'><voice name='%s'>",
+		namespaces,
+		c.locale(),
+		c.config.Voice,
+	) + before
+	after += "</voice></speak>"
+	return before, after
+}
+
+// spokenTextOffset converts the TextOffset reported by the service, a
+// character offset in the SSML document, to the character offset in the
+// spoken text: text without its markup. before is the SSML before text. It
+// returns -1 when the offset is not inside text.
+func spokenTextOffset(before, text string, offset int) int {
+	offset -= utf8.RuneCountInString(before)
+	if offset < 0 {
+		return -1
+	}
+	end := runeIndex(text, 0, offset)
+	if end < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(plainText(text[:end]))
+}
+
+// locale returns the language of the SSML document
+func (c *Communicate) locale() string {
+	if c.config.Locale != "" {
+		return c.config.Locale
+	}
+	if locale := LocaleFromVoice(c.config.Voice); locale != "" {
+		return locale
+	}
+	return "en-US"
+}
+
+// ssmlText returns the text with lang spans wrapped in <lang> elements,
+// lexicon words rewritten, and the rest normalized
+func (c *Communicate) ssmlText() string {
+	text := c.config.Text
+	if len(c.config.LangSpans) > 0 {
+		var b strings.Builder
+		last := 0
+		for _, span := range sortedLangSpans(c.config.LangSpans) {
+			b.WriteString(text[last:span.Start])
+			fmt.Fprintf(&b, "<lang xml:lang='%s'>%s</lang>", span.Lang, text[span.Start:span.End])
+			last = span.End
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+
+	// Lexicons see the text as it was written, so an entry like "Dr." is
+	// not lost to normalization, and normalizers skip the words they rewrote
+	locale := c.locale()
+	for _, lexicon := range c.config.Lexicons {
+		if lexicon.MatchesLocale(locale) {
+			text = lexicon.Apply(text)
+		}
+	}
+
+	var chain NormalizerChain
+	if c.config.Normalize {
+		chain = NormalizerForLocale(locale)
+	}
+	chain = append(chain, c.config.Normalizers...)
+	if len(chain) > 0 {
+		text = mapTextSegments(text, chain.Normalize)
+	}
+	return text
 }
 
 // getHeadersAndData extracts headers and data from binary message
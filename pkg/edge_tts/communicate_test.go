@@ -3,8 +3,17 @@ package edge_tts
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
 )
 
 // TestNewCommunicate 测试 NewCommunicate 函数
@@ -118,6 +127,9 @@ func TestCreateSSML(t *testing.T) {
 		rate   string
 		volume string
 		pitch  string
+		style  string
+		locale string
+		spans  []LangSpan
 		want   string
 	}{
 		{
@@ -136,7 +148,37 @@ func TestCreateSSML(t *testing.T) {
 			rate:   "+10%",
 			volume: "+20%",
 			pitch:  "+5Hz",
-			want:   "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-US'><voice name='zh-CN-XiaoxiaoNeural'><prosody pitch='+5Hz' rate='+10%' volume='+20%'>Hello, world!</prosody></voice></speak>",
+			want:   "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='zh-CN'><voice name='zh-CN-XiaoxiaoNeural'><prosody pitch='+5Hz' rate='+10%' volume='+20%'>Hello, world!</prosody></voice></speak>",
+		},
+		{
+			name:   "指定语言",
+			text:   "Hello, world!",
+			voice:  "en-US-EmmaMultilingualNeural",
+			rate:   "+0%",
+			volume: "+0%",
+			pitch:  "+0Hz",
+			locale: "en-GB",
+			want:   "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-GB'><voice name='en-US-EmmaMultilingualNeural'><prosody pitch='+0Hz' rate='+0%' volume='+0%'>Hello, world!</prosody></voice></speak>",
+		},
+		{
+			name:   "说话风格",
+			text:   "Hello, world!",
+			voice:  "en-US-AriaNeural",
+			rate:   "+0%",
+			volume: "+0%",
+			pitch:  "+0Hz",
+			style:  "cheerful",
+			want:   "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xmlns:mstts='https://www.w3.org/2001/mstts' xml:lang='en-US'><voice name='en-US-AriaNeural'><mstts:express-as style='cheerful'><prosody pitch='+0Hz' rate='+0%' volume='+0%'>Hello, world!</prosody></mstts:express-as></voice></speak>",
+		},
+		{
+			name:   "多语言片段",
+			text:   "Say bonjour and guten Tag.",
+			voice:  "en-US-EmmaMultilingualNeural",
+			rate:   "+0%",
+			volume: "+0%",
+			pitch:  "+0Hz",
+			spans:  []LangSpan{{Start: 16, End: 25, Lang: "de-DE"}, {Start: 4, End: 11, Lang: "fr-FR"}},
+			want:   "<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-US'><voice name='en-US-EmmaMultilingualNeural'><prosody pitch='+0Hz' rate='+0%' volume='+0%'>Say <lang xml:lang='fr-FR'>bonjour</lang> and <lang xml:lang='de-DE'>guten Tag</lang>.</prosody></voice></speak>",
 		},
 	}
 
@@ -144,11 +186,14 @@ func TestCreateSSML(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			c := &Communicate{
 				config: &TTSConfig{
-					Text:   tt.text,
-					Voice:  tt.voice,
-					Rate:   tt.rate,
-					Volume: tt.volume,
-					Pitch:  tt.pitch,
+					Text:      tt.text,
+					Voice:     tt.voice,
+					Rate:      tt.rate,
+					Volume:    tt.volume,
+					Pitch:     tt.pitch,
+					Style:     tt.style,
+					Locale:    tt.locale,
+					LangSpans: tt.spans,
 				},
 			}
 
@@ -160,6 +205,62 @@ func TestCreateSSML(t *testing.T) {
 	}
 }
 
+// TestLocaleFromVoice 测试从语音名称解析语言
+func TestLocaleFromVoice(t *testing.T) {
+	tests := map[string]string{
+		"en-US-EmmaMultilingualNeural":                                     "en-US",
+		"zh-CN-liaoning-XiaobeiNeural":                                     "zh-CN-liaoning",
+		"iu-Latn-CA-SiqiniqNeural":                                         "iu-Latn-CA",
+		"Microsoft Server Speech Text to Speech Voice (en-US, EmmaNeural)": "en-US",
+		"Emma": "",
+	}
+	for voice, want := range tests {
+		if got := LocaleFromVoice(voice); got != want {
+			t.Errorf("LocaleFromVoice(%q) = %q, want %q", voice, got, want)
+		}
+	}
+}
+
+// TestValidateLangSpans 测试语言片段校验
+func TestValidateLangSpans(t *testing.T) {
+	config := NewTTSConfig("Hello bonjour", "en-US-EmmaMultilingualNeural")
+	config.LangSpans = []LangSpan{{Start: 6, End: 13, Lang: "fr-FR"}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	config.LangSpans = []LangSpan{{Start: 6, End: 20, Lang: "fr-FR"}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject span past end of text")
+	}
+
+	config.LangSpans = []LangSpan{{Start: 0, End: 8, Lang: "fr-FR"}, {Start: 6, End: 13, Lang: "fr-FR"}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject overlapping spans")
+	}
+
+	config = NewTTSConfig("Hello 你好", "en-US-EmmaMultilingualNeural")
+	config.LangSpans = []LangSpan{{Start: 6, End: 10, Lang: "zh-CN"}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() should reject span splitting a character")
+	}
+	config.LangSpans = []LangSpan{{Start: 6, End: 12, Lang: "zh-CN"}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+// TestStreamInvalidConfig 测试无效配置由 Stream 返回错误而不是 panic
+func TestStreamInvalidConfig(t *testing.T) {
+	c := NewCommunicate("Hello", "en-US-EmmaMultilingualNeural", WithLangSpans(LangSpan{Start: 2, End: 20, Lang: "fr-FR"}))
+	if _, err := c.Stream(context.Background()); err == nil {
+		t.Error("Stream() should return the validation error")
+	}
+	if err := c.Save(context.Background(), filepath.Join(t.TempDir(), "out.mp3"), ""); err == nil {
+		t.Error("Save() should return the validation error")
+	}
+}
+
 // TestGetHeadersAndData 测试 getHeadersAndData 函数
 func TestGetHeadersAndData(t *testing.T) {
 	tests := []struct {
@@ -292,3 +393,182 @@ func TestCommunicateSave(t *testing.T) {
 	// 注意：完整的 Save 方法测试需要 mock Stream 方法，
 	// 这超出了简单测试的范围，需要更复杂的测试框架
 }
+
+// fixedIDs 是返回固定标识符的 IDGenerator
+type fixedIDs struct{}
+
+func (fixedIDs) ConnectionID() string { return "conn-1" }
+func (fixedIDs) RequestID() string    { return "req-1" }
+func (fixedIDs) MUID() string         { return "MUID-1" }
+
+// wireRequest 是测试服务器收到的连接请求和消息
+type wireRequest struct {
+	query, cookie, origin string
+	frames                []string
+}
+
+// newWireServer 启动模拟的语音合成服务，返回 audio 音频和 word 的字边界
+func newWireServer(t *testing.T, audio []byte, word string) (string, <-chan wireRequest) {
+	handler, requests := newWireHandler(t, audio, word)
+	return wireURL(t, handler), requests
+}
+
+// wireURL 用 handler 启动模拟服务，返回它的 WebSocket 地址
+func wireURL(t *testing.T, handler http.Handler) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/edge/v1?TrustedClientToken=" + TrustedClientToken
+}
+
+// newWireHandler 返回模拟语音合成服务的 handler 和它收到的请求
+func newWireHandler(t *testing.T, audio []byte, word string) (http.Handler, <-chan wireRequest) {
+	requests := make(chan wireRequest, 1)
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := wireRequest{query: r.URL.RawQuery, cookie: r.Header.Get("Cookie"), origin: r.Header.Get("Origin")}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		for len(req.frames) < 2 {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				t.Errorf("read failed: %v", err)
+				return
+			}
+			req.frames = append(req.frames, string(message))
+		}
+		requests <- req
+
+		metadata := `{"Metadata":[{"Type":"WordBoundary","Data":{"Offset":1000000,"Duration":2000000,"text":{"Text":"` + word + `","Length":5}}}]}`
+		conn.WriteMessage(websocket.TextMessage, []byte("X-RequestId:req-1\r\nContent-Type:application/json; charset=utf-8\r\nPath:audio.metadata\r\n\r\n"+metadata))
+		header := "X-RequestId:req-1\r\nContent-Type:audio/mpeg\r\nPath:audio\r\n"
+		binaryFrame := binary.BigEndian.AppendUint16(nil, uint16(len(header)))
+		binaryFrame = append(append(binaryFrame, header...), audio...)
+		conn.WriteMessage(websocket.BinaryMessage, binaryFrame)
+		conn.WriteMessage(websocket.TextMessage, []byte("X-RequestId:req-1\r\nPath:turn.end\r\n\r\n{}"))
+	})
+	return handler, requests
+}
+
+// TestStreamWire 测试固定时钟和标识符时发送的请求和消息与预期完全一致
+func TestStreamWire(t *testing.T) {
+	audio := silentMP3(480 * time.Millisecond)
+	wsURL, requests := newWireServer(t, audio, "Hello")
+
+	now := time.Date(2024, 12, 4, 10, 30, 45, 0, time.UTC)
+	c := NewCommunicate("Hello", "en-US-AvaNeural",
+		WithClientProfile(DefaultClientProfile()),
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithIDGenerator(fixedIDs{}),
+	)
+	c.wsURL = wsURL
+
+	dir := t.TempDir()
+	audioPath, subPath := filepath.Join(dir, "out.mp3"), filepath.Join(dir, "out.srt")
+	if err := c.Save(context.Background(), audioPath, subPath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	req := <-requests
+	wantQuery := "TrustedClientToken=" + TrustedClientToken + "&ConnectionId=conn-1" +
+		"&Sec-MS-GEC=97CFEC7475D1F30CA77EE8782B8D16B012621819A8F9D484A0353BF78A7262AC" +
+		"&Sec-MS-GEC-Version=" + SEC_MS_GEC_VERSION
+	if req.query != wantQuery {
+		t.Errorf("query = %s\nwant %s", req.query, wantQuery)
+	}
+	if req.cookie != "muid=MUID-1;" || req.origin != WSSHeaders["Origin"] {
+		t.Errorf("cookie = %q, origin = %q", req.cookie, req.origin)
+	}
+
+	date := "Wed Dec 04 2024 10:30:45 GMT+0000 (Coordinated Universal Time)"
+	wantFrames := []string{
+		"X-Timestamp:" + date + "\r\nContent-Type:application/json; charset=utf-8\r\nPath:speech.config\r\n\r\n" +
+			`{"context":{"synthesis":{"audio":{"metadataoptions":{"sentenceBoundaryEnabled":"false","wordBoundaryEnabled":"true"},"outputFormat":"audio-24khz-48kbitrate-mono-mp3"}}}}` + "\r\n",
+		"X-RequestId:req-1\r\nContent-Type:application/ssml+xml\r\nX-Timestamp:" + date + "Z\r\nPath:ssml\r\n\r\n" +
+			"<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='en-US'><voice name='en-US-AvaNeural'>" +
+			"<prosody pitch='+0Hz' rate='+0%' volume='+0%'>Hello</prosody></voice></speak>",
+	}
+	for i, want := range wantFrames {
+		if req.frames[i] != want {
+			t.Errorf("frame %d = %q\nwant %q", i, req.frames[i], want)
+		}
+	}
+
+	if got, _ := os.ReadFile(audioPath); !bytes.Equal(got, audio) {
+		t.Errorf("audio = %d bytes, want the %d bytes sent", len(got), len(audio))
+	}
+	want := "1\n00:00:00,100 --> 00:00:00,300\nHello\n\n"
+	if got, _ := os.ReadFile(subPath); string(got) != want {
+		t.Errorf("subtitles = %q, want %q", got, want)
+	}
+}
+
+// TestStreamClockSkew 测试握手返回 403 时按服务器日期调整时钟并重试一次
+func TestStreamClockSkew(t *testing.T) {
+	handler, requests := newWireHandler(t, silentMP3(480*time.Millisecond), "Hello")
+	serverTime := time.Now().Add(time.Hour).UTC()
+	var tokens []string
+	wsURL := wireURL(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.URL.Query().Get("Sec-MS-GEC"))
+		if len(tokens) == 1 {
+			w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+
+	c := NewCommunicate("Hello", "en-US-AvaNeural", WithClientProfile(DefaultClientProfile()))
+	c.wsURL = wsURL
+	dir := t.TempDir()
+	if err := c.Save(context.Background(), filepath.Join(dir, "out.mp3"), ""); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	<-requests
+
+	skew := c.clock.(*serverClock).skew
+	if skew < time.Hour-2*time.Second || skew > time.Hour+2*time.Second {
+		t.Errorf("skew = %v, want about an hour", skew)
+	}
+	if len(tokens) != 2 || tokens[0] == tokens[1] {
+		t.Errorf("tokens = %q, want a retry with the token of the server time", tokens)
+	}
+
+	// 调整后仍然返回 403 时不再重试
+	tokens = nil
+	c = NewCommunicate("Hello", "en-US-AvaNeural", WithClientProfile(DefaultClientProfile()))
+	c.wsURL = wireURL(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.URL.Query().Get("Sec-MS-GEC"))
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	if err := c.Save(context.Background(), filepath.Join(dir, "forbidden.mp3"), ""); err == nil || len(tokens) != 2 {
+		t.Errorf("Save() error = %v after %d requests, want an error after 2", err, len(tokens))
+	}
+}
+
+// TestSpokenTextOffset 测试把服务报告的 SSML 位置换算为朗读文本中的位置
+func TestSpokenTextOffset(t *testing.T) {
+	c := NewCommunicate("Fish &amp; <emphasis>chips</emphasis> 好吃", "zh-CN-XiaoxiaoNeural")
+	before, _ := c.ssmlEnvelope()
+	text := c.ssmlText()
+	n := utf8.RuneCountInString(before)
+	tests := []struct {
+		offset int
+		want   int
+	}{
+		{n, 0},       // Fish
+		{n + 21, 7},  // chips
+		{n + 38, 13}, // 好吃
+		{n - 1, -1},
+		{n + 100, -1},
+	}
+	for _, tt := range tests {
+		if got := spokenTextOffset(before, text, tt.offset); got != tt.want {
+			t.Errorf("spokenTextOffset(%d) = %d, want %d", tt.offset-n, got, tt.want)
+		}
+	}
+}
@@ -15,6 +15,8 @@ const (
 	SEC_MS_GEC_VERSION   = "1-" + ChromiumFullVersion
 )
 
+// The headers below belong to the built-in browser profile. Requests use
+// the ClientProfile from LoadClientConfig, which can replace them.
 var (
 	BaseHeaders = map[string]string{
 		"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36" +
@@ -0,0 +1,332 @@
+package edge_tts
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultDialoguePause is the silence inserted between dialogue turns
+const DefaultDialoguePause = 400 * time.Millisecond
+
+// DefaultDialogueCueWords is the number of words merged into one dialogue subtitle
+const DefaultDialogueCueWords = 10
+
+// DialogueTurn is one line spoken by one speaker
+type DialogueTurn struct {
+	Speaker string `json:"speaker"`
+	Text    string `json:"text"`
+}
+
+// SpeakerVoice describes how a dialogue speaker sounds. Empty prosody
+// fields keep the service defaults.
+type SpeakerVoice struct {
+	Voice  string `json:"voice"`
+	Style  string `json:"style,omitempty"`
+	Rate   string `json:"rate,omitempty"`
+	Volume string `json:"volume,omitempty"`
+	Pitch  string `json:"pitch,omitempty"`
+}
+
+// options returns the synthesis options for the speaker
+func (v SpeakerVoice) options() []Option {
+	var opts []Option
+	if v.Rate != "" {
+		opts = append(opts, WithRate(v.Rate))
+	}
+	if v.Volume != "" {
+		opts = append(opts, WithVolume(v.Volume))
+	}
+	if v.Pitch != "" {
+		opts = append(opts, WithPitch(v.Pitch))
+	}
+	if v.Style != "" {
+		opts = append(opts, WithStyle(v.Style))
+	}
+	return opts
+}
+
+// streamFunc synthesizes one piece of text, it is Communicate.Stream by default
+type streamFunc func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error)
+
+// communicateStream is the default streamFunc
+func communicateStream(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+	return NewCommunicate(text, voice, opts...).Stream(ctx)
+}
+
+// Dialogue renders a multi-speaker script into one continuous audio track
+type Dialogue struct {
+	Turns    []DialogueTurn
+	Speakers map[string]SpeakerVoice
+	// Pause is the silence between two turns
+	Pause time.Duration
+	// CueWords is the number of words merged into one subtitle, 0 keeps
+	// one subtitle per word
+	CueWords int
+	// Options are applied to every turn before the speaker's own options,
+	// e.g. WithLexicon
+	Options []Option
+
+	stream streamFunc
+}
+
+// NewDialogue creates a new Dialogue with the default pause and cue size
+func NewDialogue(turns []DialogueTurn, speakers map[string]SpeakerVoice) *Dialogue {
+	if speakers == nil {
+		speakers = make(map[string]SpeakerVoice)
+	}
+	return &Dialogue{
+		Turns:    turns,
+		Speakers: speakers,
+		Pause:    DefaultDialoguePause,
+		CueWords: DefaultDialogueCueWords,
+		stream:   communicateStream,
+	}
+}
+
+// dialogueLine matches "Speaker: line", the full-width colon is accepted
+// too. The speaker is a short label without spaces or digits, so a line
+// like "We meet at 10:30" is not taken for a speaker.
+var dialogueLine = regexp.MustCompile(`^([^\s\d:：]{1,32})[:：]\s*(.*)$`)
+
+// ParseDialogueScript parses a plain-text script. Every "Speaker: line"
+// starts a new turn, other non-empty lines continue the previous turn.
+// Blank lines and lines starting with '#' are ignored.
+func ParseDialogueScript(r io.Reader) (*Dialogue, error) {
+	var turns []DialogueTurn
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := dialogueLine.FindStringSubmatch(line); m != nil {
+			turns = append(turns, DialogueTurn{
+				Speaker: strings.TrimSpace(m[1]),
+				Text:    strings.TrimSpace(m[2]),
+			})
+			continue
+		}
+
+		if len(turns) == 0 {
+			return nil, fmt.Errorf("line %d: expected 'Speaker: text'", lineNo)
+		}
+		last := &turns[len(turns)-1]
+		last.Text = strings.TrimSpace(last.Text + " " + line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewDialogue(turns, nil), nil
+}
+
+// ParseDialogueJSON parses the JSON form of a script. It is either an
+// array of turns, or an object with "turns", "speakers" and an optional
+// "pause" such as "500ms".
+func ParseDialogueJSON(data []byte) (*Dialogue, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var turns []DialogueTurn
+		if err := json.Unmarshal(data, &turns); err != nil {
+			return nil, fmt.Errorf("parse dialogue failed: %w", err)
+		}
+		return NewDialogue(turns, nil), nil
+	}
+
+	var doc struct {
+		Turns    []DialogueTurn          `json:"turns"`
+		Speakers map[string]SpeakerVoice `json:"speakers"`
+		Pause    string                  `json:"pause"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse dialogue failed: %w", err)
+	}
+
+	d := NewDialogue(doc.Turns, doc.Speakers)
+	if doc.Pause != "" {
+		pause, err := time.ParseDuration(doc.Pause)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pause %q: %w", doc.Pause, err)
+		}
+		d.Pause = pause
+	}
+	return d, nil
+}
+
+// LoadDialogue reads a script from path, files ending in .json use the
+// JSON form
+func LoadDialogue(path string) (*Dialogue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ParseDialogueJSON(data)
+	}
+	return ParseDialogueScript(bytes.NewReader(data))
+}
+
+// Synthesize renders all turns to audio, one after another with Pause in
+// between. If sub is not nil it receives the subtitles, shifted to the
+// position of each turn and labelled with the speaker.
+func (d *Dialogue) Synthesize(ctx context.Context, audio io.Writer, sub *SubMaker) error {
+	stream := d.stream
+	if stream == nil {
+		stream = communicateStream
+	}
+
+	var offset time.Duration
+	written := false
+	for i, turn := range d.Turns {
+		if strings.TrimSpace(turn.Text) == "" {
+			continue
+		}
+		voice, ok := d.Speakers[turn.Speaker]
+		if !ok || voice.Voice == "" {
+			return fmt.Errorf("no voice for speaker %q", turn.Speaker)
+		}
+
+		opts := append(append([]Option{}, d.Options...), voice.options()...)
+		ch, err := stream(ctx, turn.Text, voice.Voice, opts...)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		turnSub := NewSubMaker()
+		for chunk := range ch {
+			switch chunk.Type {
+			case "error":
+				return fmt.Errorf("error during streaming turn %d: %s", i+1, string(chunk.Data))
+			case "audio":
+				data = append(data, chunk.Data...)
+			case "WordBoundary":
+				if err := turnSub.Feed(chunk); err != nil {
+					return fmt.Errorf("error feeding chunk: %v", err)
+				}
+			}
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("turn %d: %w", i+1, ErrNoAudioReceived)
+		}
+
+		if written && d.Pause > 0 {
+			silence := silentMP3(d.Pause)
+			if _, err := audio.Write(silence); err != nil {
+				return err
+			}
+			offset += mp3Duration(silence)
+		}
+		if _, err := audio.Write(data); err != nil {
+			return err
+		}
+
+		if sub != nil {
+			if d.config().AlignSubtitles {
+				turnSub.AlignText(turn.Text)
+			}
+			if err := d.mergeCues(turnSub); err != nil {
+				return err
+			}
+			for _, cue := range turnSub.cues {
+				cue.Index = len(sub.cues) + 1
+				cue.Start += offset
+				cue.End += offset
+				cue.Speaker = turn.Speaker
+				for i := range cue.Words {
+					cue.Words[i].Start += offset
+					cue.Words[i].End += offset
+				}
+				sub.cues = append(sub.cues, cue)
+			}
+		}
+
+		offset += mp3Duration(data)
+		written = true
+	}
+
+	if sub != nil {
+		// Readability runs on the whole dialogue so cues can extend into the pauses
+		if readability := d.config().SubtitleReadability; readability != nil {
+			if err := sub.ApplyReadability(*readability); err != nil {
+				return err
+			}
+		}
+		sub.lrcTags = LRCTags{Artist: strings.Join(d.speakerNames(), ", "), Length: offset}
+	}
+	return nil
+}
+
+// config returns the configuration set by Options
+func (d *Dialogue) config() *TTSConfig {
+	config := &TTSConfig{}
+	for _, opt := range d.Options {
+		opt(config)
+	}
+	return config
+}
+
+// mergeCues groups the words of a turn, WithSubtitleMerge in Options
+// takes precedence over CueWords
+func (d *Dialogue) mergeCues(sub *SubMaker) error {
+	if config := d.config(); config.SubtitleMerge != nil {
+		return sub.MergeCuesWith(*config.SubtitleMerge)
+	}
+	if d.CueWords > 0 {
+		return sub.MergeCues(d.CueWords)
+	}
+	return nil
+}
+
+// speakerNames returns the speakers in the order they first speak
+func (d *Dialogue) speakerNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, turn := range d.Turns {
+		if !seen[turn.Speaker] && strings.TrimSpace(turn.Text) != "" {
+			seen[turn.Speaker] = true
+			names = append(names, turn.Speaker)
+		}
+	}
+	return names
+}
+
+// Save renders the dialogue to audioPath and, if subtitlePath is not
+// empty, writes subtitles with speaker names in the format of its
+// extension (.vtt for WebVTT, .ass/.ssa for ASS, SRT otherwise)
+func (d *Dialogue) Save(ctx context.Context, audioPath string, subtitlePath string) error {
+	audioFile, err := os.Create(audioPath)
+	if err != nil {
+		return err
+	}
+	defer audioFile.Close()
+
+	var sub *SubMaker
+	if subtitlePath != "" {
+		sub = NewSubMaker()
+	}
+
+	if err := d.Synthesize(ctx, audioFile, sub); err != nil {
+		return err
+	}
+
+	if sub != nil {
+		if err := os.WriteFile(subtitlePath, []byte(sub.GetSubtitles(subtitlePath)), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
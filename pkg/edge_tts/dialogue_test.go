@@ -0,0 +1,138 @@
+package edge_tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeStream 返回一个按单词生成音频和 WordBoundary 的 streamFunc，
+// 每个单词 240ms（10 个静音帧）
+func fakeStream(calls *[]string) streamFunc {
+	return func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+		*calls = append(*calls, voice+"|"+text)
+		words := strings.Fields(text)
+		ch := make(chan TTSChunk, len(words)*2+1)
+		for i, word := range words {
+			ch <- TTSChunk{Type: "audio", Data: silentMP3(240 * time.Millisecond)}
+			ch <- TTSChunk{
+				Type:     "WordBoundary",
+				Offset:   float64(i) * 2400000,
+				Duration: 2000000,
+				Text:     word,
+			}
+		}
+		ch <- TTSChunk{Type: "end"}
+		close(ch)
+		return ch, nil
+	}
+}
+
+// TestParseDialogueScript 测试解析对话脚本
+func TestParseDialogueScript(t *testing.T) {
+	script := `# episode 1
+Alice: Hello there.
+Bob: Hi Alice,
+how are you?
+We meet at 10:30 tomorrow.
+
+小明：你好
+`
+	d, err := ParseDialogueScript(strings.NewReader(script))
+	if err != nil {
+		t.Fatalf("ParseDialogueScript() error = %v", err)
+	}
+
+	want := []DialogueTurn{
+		{Speaker: "Alice", Text: "Hello there."},
+		{Speaker: "Bob", Text: "Hi Alice, how are you? We meet at 10:30 tomorrow."},
+		{Speaker: "小明", Text: "你好"},
+	}
+	if len(d.Turns) != len(want) {
+		t.Fatalf("got %d turns, want %d: %v", len(d.Turns), len(want), d.Turns)
+	}
+	for i := range want {
+		if d.Turns[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, d.Turns[i], want[i])
+		}
+	}
+
+	if _, err := ParseDialogueScript(strings.NewReader("no speaker here")); err == nil {
+		t.Error("ParseDialogueScript() should fail without a speaker")
+	}
+}
+
+// TestParseDialogueJSON 测试解析 JSON 对话
+func TestParseDialogueJSON(t *testing.T) {
+	data := `{
+		"pause": "1s",
+		"speakers": {"Alice": {"voice": "en-US-AvaNeural", "style": "cheerful", "rate": "+10%"}},
+		"turns": [{"speaker": "Alice", "text": "Hello"}]
+	}`
+	d, err := ParseDialogueJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDialogueJSON() error = %v", err)
+	}
+	if d.Pause != time.Second {
+		t.Errorf("Pause = %v, want 1s", d.Pause)
+	}
+	if v := d.Speakers["Alice"]; v.Voice != "en-US-AvaNeural" || v.Style != "cheerful" || v.Rate != "+10%" {
+		t.Errorf("Speakers[Alice] = %+v", v)
+	}
+	if len(d.Turns) != 1 || d.Turns[0].Text != "Hello" {
+		t.Errorf("Turns = %+v", d.Turns)
+	}
+
+	d, err = ParseDialogueJSON([]byte(`[{"speaker": "Bob", "text": "Hi"}]`))
+	if err != nil {
+		t.Fatalf("ParseDialogueJSON() array error = %v", err)
+	}
+	if len(d.Turns) != 1 || d.Turns[0].Speaker != "Bob" {
+		t.Errorf("Turns = %+v", d.Turns)
+	}
+}
+
+// TestDialogueSynthesize 测试对话合成时的字幕偏移和说话人
+func TestDialogueSynthesize(t *testing.T) {
+	var calls []string
+	d := NewDialogue([]DialogueTurn{
+		{Speaker: "Alice", Text: "Hello there"},
+		{Speaker: "Bob", Text: "Hi"},
+	}, map[string]SpeakerVoice{
+		"Alice": {Voice: "en-US-AvaNeural"},
+		"Bob":   {Voice: "en-US-AndrewNeural"},
+	})
+	d.Pause = 480 * time.Millisecond
+	d.stream = fakeStream(&calls)
+
+	var audio bytes.Buffer
+	sub := NewSubMaker()
+	if err := d.Synthesize(context.Background(), &audio, sub); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	if len(calls) != 2 || calls[0] != "en-US-AvaNeural|Hello there" || calls[1] != "en-US-AndrewNeural|Hi" {
+		t.Errorf("stream calls = %v", calls)
+	}
+
+	// 两个单词 + 停顿 + 一个单词
+	if got, want := mp3Duration(audio.Bytes()), 480*time.Millisecond+480*time.Millisecond+240*time.Millisecond; got != want {
+		t.Errorf("audio duration = %v, want %v", got, want)
+	}
+
+	want := "1\n00:00:00,000 --> 00:00:00,440\nAlice: Hello there\n\n" +
+		"2\n00:00:00,960 --> 00:00:01,160\nBob: Hi\n\n"
+	if got := sub.GetSRT(); got != want {
+		t.Errorf("GetSRT() = %q, want %q", got, want)
+	}
+	if got := sub.lrcTags; got.Artist != "Alice, Bob" || got.Length != 1200*time.Millisecond {
+		t.Errorf("lrcTags = %+v", got)
+	}
+
+	d.Turns = append(d.Turns, DialogueTurn{Speaker: "Carol", Text: "Hey"})
+	if err := d.Synthesize(context.Background(), &audio, nil); err == nil {
+		t.Error("Synthesize() should fail for a speaker without a voice")
+	}
+}
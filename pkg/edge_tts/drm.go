@@ -7,33 +7,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 )
 
-// clockSkewSeconds 存储时钟偏差（秒）
-var clockSkewSeconds float64
-
-// clockSkewLock 用于保护 clockSkewSeconds 的并发访问
-var clockSkewLock sync.RWMutex
-
-// adjustClockSkew 调整时钟偏差
-func adjustClockSkew(date string) error {
-	// 解析 RFC 2616 格式的日期
-	serverTime, err := parseRFC2616Date(date)
-	if err != nil {
-		return err
-	}
-
-	// 计算时钟偏差
-	clientTime := time.Now().UTC().Unix()
-	clockSkewLock.Lock()
-	clockSkewSeconds = float64(serverTime - clientTime)
-	clockSkewLock.Unlock()
-
-	return nil
-}
-
 // parseRFC2616Date 解析 RFC 2616 格式的日期
 func parseRFC2616Date(date string) (int64, error) {
 	// 尝试解析 RFC 2616 格式的日期
@@ -45,18 +21,10 @@ func parseRFC2616Date(date string) (int64, error) {
 	return t.Unix(), nil
 }
 
-// getUnixTimestamp 获取当前 Unix 时间戳（秒）
-func getUnixTimestamp() int64 {
-	clockSkewLock.RLock()
-	skew := clockSkewSeconds
-	clockSkewLock.RUnlock()
-	return time.Now().UTC().Unix() + int64(skew)
-}
-
-// generateSecMsGec 生成 Sec-MS-GEC token
-func generateSecMsGec() string {
-	// 获取当前时间戳（Unix 时间戳，秒）
-	timestamp := getUnixTimestamp()
+// secMsGec 使用 trustedClientToken 生成 now 时刻的 Sec-MS-GEC token
+func secMsGec(now time.Time, trustedClientToken string) string {
+	// 获取时间戳（Unix 时间戳，秒）
+	timestamp := now.UTC().Unix()
 
 	// 转换为 Windows 文件时间（从 1601-01-01 开始的 100 纳秒间隔）
 	ticks := (timestamp + 11644473600) * 10000000
@@ -65,7 +33,7 @@ func generateSecMsGec() string {
 	ticks = ticks - (ticks % (300 * 10000000))
 
 	// 创建要哈希的字符串
-	strToHash := fmt.Sprintf("%d%s", ticks, TrustedClientToken)
+	strToHash := fmt.Sprintf("%d%s", ticks, trustedClientToken)
 
 	// 计算 SHA256 哈希
 	hash := sha256.Sum256([]byte(strToHash))
@@ -74,8 +42,8 @@ func generateSecMsGec() string {
 	return strings.ToUpper(hex.EncodeToString(hash[:]))
 }
 
-// handleClientResponseError 处理客户端响应错误
-func handleClientResponseError(resp *http.Response) error {
+// handleClientResponseError 处理客户端响应错误，按服务器日期调整 clock 的时钟偏差
+func handleClientResponseError(resp *http.Response, clock *serverClock) error {
 	// 获取服务器日期
 	date := resp.Header.Get("Date")
 	if date == "" {
@@ -83,7 +51,7 @@ func handleClientResponseError(resp *http.Response) error {
 	}
 
 	// 调整时钟偏差
-	return adjustClockSkew(date)
+	return clock.adjustClockSkew(date)
 }
 
 // generateMUID 生成随机 MUID (Microsoft User ID)
@@ -94,16 +62,16 @@ func generateMUID() string {
 }
 
 // headersWithMUID 返回带 MUID Cookie 的 headers
-func headersWithMUID(headers http.Header) http.Header {
+func headersWithMUID(headers http.Header, muid string) http.Header {
 	combined := headers.Clone()
-	combined.Set("Cookie", "muid="+generateMUID()+";")
+	combined.Set("Cookie", "muid="+muid+";")
 	return combined
 }
 
-// dateToString 返回 JavaScript 风格的日期字符串
+// dateToString 返回 t 的 JavaScript 风格的日期字符串
 // 格式: "Wed Dec 04 2024 10:30:45 GMT+0000 (Coordinated Universal Time)"
-func dateToString() string {
-	now := time.Now().UTC()
+func dateToString(t time.Time) string {
+	now := t.UTC()
 	// JavaScript 风格的日期格式
 	return now.Format("Mon Jan 02 2006 15:04:05") + " GMT+0000 (Coordinated Universal Time)"
 }
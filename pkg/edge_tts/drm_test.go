@@ -7,60 +7,44 @@ import (
 
 func TestAdjustClockSkew(t *testing.T) {
 	// 测试调整时钟偏差
+	clock := &serverClock{}
 	date := time.Now().UTC().Format(time.RFC1123)
-	err := adjustClockSkew(date)
+	err := clock.adjustClockSkew(date)
 	if err != nil {
 		t.Errorf("adjustClockSkew failed: %v", err)
 	}
 
 	// 测试正偏差
-	clockSkewSeconds = 0
 	date = time.Now().Add(10 * time.Second).UTC().Format(time.RFC1123)
-	err = adjustClockSkew(date)
+	err = clock.adjustClockSkew(date)
 	if err != nil {
 		t.Errorf("adjustClockSkew failed: %v", err)
 	}
-	if clockSkewSeconds != 10 {
-		t.Errorf("expected clockSkewSeconds to be 10, got %f", clockSkewSeconds)
+	if clock.skew != 10*time.Second {
+		t.Errorf("expected skew to be 10s, got %v", clock.skew)
 	}
 
-	// 测试负偏差
-	clockSkewSeconds = 0
+	// 测试负偏差，另一个时钟不受影响
+	other := &serverClock{}
 	date = time.Now().Add(-5 * time.Second).UTC().Format(time.RFC1123)
-	err = adjustClockSkew(date)
+	err = other.adjustClockSkew(date)
 	if err != nil {
 		t.Errorf("adjustClockSkew failed: %v", err)
 	}
-	if clockSkewSeconds != -5 {
-		t.Errorf("expected clockSkewSeconds to be -5, got %f", clockSkewSeconds)
+	if other.skew != -5*time.Second || clock.skew != 10*time.Second {
+		t.Errorf("expected skews -5s and 10s, got %v and %v", other.skew, clock.skew)
 	}
 }
 
-func TestGetUnixTimestamp(t *testing.T) {
-	// 测试获取时间戳
-	now := time.Now().Unix()
-	timestamp := getUnixTimestamp()
-
-	// 检查时间戳是否在合理范围内（当前时间前后5秒）
-	if timestamp < now-5 || timestamp > now+5 {
-		t.Errorf("timestamp %d is not within expected range (%d to %d)", timestamp, now-5, now+5)
-	}
-}
-
-func TestGenerateSecMsGec(t *testing.T) {
-	// 测试生成 Sec-MS-GEC token
-	token := generateSecMsGec()
-
-	// 检查 token 格式
-	if len(token) != 64 {
-		t.Errorf("token length should be 64, got %d", len(token))
+func TestSecMsGec(t *testing.T) {
+	// 测试生成 Sec-MS-GEC token，同一个五分钟内的时间生成相同的 token
+	clock := ClockFunc(func() time.Time { return time.Date(2024, 12, 4, 10, 30, 45, 0, time.UTC) })
+	token := secMsGec(clock.Now(), TrustedClientToken)
+	if token != "97CFEC7475D1F30CA77EE8782B8D16B012621819A8F9D484A0353BF78A7262AC" {
+		t.Errorf("secMsGec() = %s", token)
 	}
-
-	// 检查 token 是否只包含十六进制字符
-	for _, c := range token {
-		if !((c >= '0' && c <= '9') || (c >= 'A' && c <= 'F')) {
-			t.Errorf("token contains invalid character: %c", c)
-		}
+	if other := secMsGec(clock.Now().Add(-45*time.Second), TrustedClientToken); other != token {
+		t.Errorf("secMsGec() in the same five minutes = %s, want %s", other, token)
 	}
 }
 
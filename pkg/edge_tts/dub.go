@@ -0,0 +1,246 @@
+package edge_tts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDubMaxRate is the highest rate, in percent, a cue is sped up to
+// when its speech does not fit
+const DefaultDubMaxRate = 50
+
+// dubFitAttempts is how many times a cue is synthesized while fitting it
+// into its slot. The speaking time does not scale exactly with the rate, so
+// the first guess can still be a little long.
+const dubFitAttempts = 3
+
+// dubRateMargin is added to the computed rate so the first faster attempt
+// usually fits
+const dubRateMargin = 5
+
+// Dub synthesizes subtitle cues into one audio track where every cue starts
+// at its subtitle time, e.g. to voice a translated SRT for a video
+type Dub struct {
+	Cues  []SubCue
+	Voice string
+	// MaxRate limits how far, in percent, a cue that overruns its slot is
+	// sped up. The rate set in Options is the starting point.
+	MaxRate int
+	// Options are applied to every cue, e.g. WithRate or WithLexicon
+	Options []Option
+
+	stream streamFunc
+}
+
+// NewDub creates a new Dub with the default rate limit
+func NewDub(cues []SubCue, voice string, opts ...Option) *Dub {
+	return &Dub{
+		Cues:    cues,
+		Voice:   voice,
+		MaxRate: DefaultDubMaxRate,
+		Options: opts,
+		stream:  communicateStream,
+	}
+}
+
+// DubCue reports how one cue was placed, times are in milliseconds
+type DubCue struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	// Start is the cue start in the subtitles
+	Start int64 `json:"start"`
+	// Slot is the time from Start until the next cue starts, or until the
+	// cue ends for the last cue
+	Slot int64 `json:"slot"`
+	// Duration is the length of the synthesized speech
+	Duration int64  `json:"duration"`
+	Rate     string `json:"rate"`
+	// Delay is how late the speech started because the previous cue overran
+	Delay int64 `json:"delay"`
+	// Overrun is how far the speech runs past the slot, 0 when it fits
+	Overrun int64 `json:"overrun"`
+}
+
+// DubReport describes the placement of every cue of a dub
+type DubReport struct {
+	Cues []DubCue `json:"cues"`
+	// Length is the length of the audio track
+	Length int64 `json:"length"`
+}
+
+// Overruns returns the cues whose speech still runs past their slot
+func (r *DubReport) Overruns() []DubCue {
+	var cues []DubCue
+	for _, cue := range r.Cues {
+		if cue.Overrun > 0 {
+			cues = append(cues, cue)
+		}
+	}
+	return cues
+}
+
+// Synthesize renders the cues to audio. Silence is inserted before each
+// cue so it starts at its subtitle time; speech that overruns its slot is
+// synthesized again at a higher rate, up to MaxRate. A cue that still does
+// not fit pushes the following cues back.
+func (d *Dub) Synthesize(ctx context.Context, audio io.Writer) (*DubReport, error) {
+	stream := d.stream
+	if stream == nil {
+		stream = communicateStream
+	}
+	config := &TTSConfig{}
+	for _, opt := range d.Options {
+		opt(config)
+	}
+	baseRate, err := parseRatePercent(config.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	cues := append([]SubCue(nil), d.Cues...)
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].Start < cues[j].Start })
+
+	report := &DubReport{}
+	var offset time.Duration
+	for i, cue := range cues {
+		text := strings.Join(strings.Fields(plainText(cue.Text)), " ")
+		if text == "" {
+			continue
+		}
+
+		slotEnd := cue.End
+		if i+1 < len(cues) {
+			slotEnd = max(slotEnd, cues[i+1].Start)
+		}
+		start := max(cue.Start, offset)
+
+		rate := baseRate
+		var data []byte
+		for attempt := 0; attempt < dubFitAttempts; attempt++ {
+			data, err = d.synthesizeCue(ctx, stream, text, rate)
+			if err != nil {
+				return nil, fmt.Errorf("cue %d: %w", cue.Index, err)
+			}
+			next, ok := fitRate(rate, mp3Duration(data), slotEnd-start, d.MaxRate)
+			// Only move on when another attempt uses the rate, so rate is
+			// always the one data was spoken at
+			if !ok || attempt+1 == dubFitAttempts {
+				break
+			}
+			rate = next
+		}
+
+		delay := max(offset-cue.Start, 0)
+		if silence := silentMP3(cue.Start - offset); silence != nil {
+			if _, err := audio.Write(silence); err != nil {
+				return nil, err
+			}
+			offset += mp3Duration(silence)
+		}
+		if _, err := audio.Write(data); err != nil {
+			return nil, err
+		}
+		duration := mp3Duration(data)
+		offset += duration
+
+		report.Cues = append(report.Cues, DubCue{
+			Index:    cue.Index,
+			Text:     text,
+			Start:    cue.Start.Milliseconds(),
+			Slot:     (slotEnd - cue.Start).Milliseconds(),
+			Duration: duration.Milliseconds(),
+			Rate:     formatRatePercent(rate),
+			Delay:    delay.Milliseconds(),
+			Overrun:  max(offset-slotEnd, 0).Milliseconds(),
+		})
+	}
+	report.Length = offset.Milliseconds()
+	return report, nil
+}
+
+// synthesizeCue returns the audio of one cue spoken at rate percent
+func (d *Dub) synthesizeCue(ctx context.Context, stream streamFunc, text string, rate int) ([]byte, error) {
+	opts := append(append([]Option{}, d.Options...), WithRate(formatRatePercent(rate)))
+	ch, err := stream(ctx, text, d.Voice, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for chunk := range ch {
+		switch chunk.Type {
+		case "error":
+			return nil, fmt.Errorf("error during streaming: %s", string(chunk.Data))
+		case "audio":
+			data = append(data, chunk.Data...)
+		}
+	}
+	if len(data) == 0 {
+		return nil, ErrNoAudioReceived
+	}
+	return data, nil
+}
+
+// fitRate returns the rate that should make speech of duration, spoken at
+// rate, fit into available. ok is false when the speech already fits or
+// the rate cannot go higher.
+func fitRate(rate int, duration, available time.Duration, maxRate int) (int, bool) {
+	if duration <= available || rate >= maxRate {
+		return rate, false
+	}
+	next := maxRate
+	if available > 0 {
+		next = int(math.Ceil(scaledRate(rate, duration, available))) + dubRateMargin
+	}
+	return min(max(next, rate+1), maxRate), true
+}
+
+// parseRatePercent parses a prosody rate such as "+10%", empty means 0
+func parseRatePercent(rate string) (int, error) {
+	if rate == "" {
+		return 0, nil
+	}
+	var percent int
+	if _, err := fmt.Sscanf(rate, "%d%%", &percent); err != nil {
+		return 0, fmt.Errorf("invalid rate %q, expected a percentage such as +10%%", rate)
+	}
+	return percent, nil
+}
+
+// formatRatePercent formats a rate the way the service expects it
+func formatRatePercent(percent int) string {
+	return fmt.Sprintf("%+d%%", percent)
+}
+
+// Save renders the dub to audioPath and, if reportPath is not empty,
+// writes the report as JSON
+func (d *Dub) Save(ctx context.Context, audioPath, reportPath string) (*DubReport, error) {
+	audioFile, err := os.Create(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer audioFile.Close()
+
+	report, err := d.Synthesize(ctx, audioFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(reportPath, append(data, '\n'), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,163 @@
+package edge_tts
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRateStream 返回一个按语速生成音频的 streamFunc，
+// 默认语速下每个单词 240ms，语速 +r% 时时长为 240ms/(1+r/100)
+func fakeRateStream(rates *[]string) streamFunc {
+	return func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+		config := &TTSConfig{}
+		for _, opt := range opts {
+			opt(config)
+		}
+		*rates = append(*rates, config.Rate)
+		rate, err := parseRatePercent(config.Rate)
+		if err != nil {
+			return nil, err
+		}
+		words := len(strings.Fields(text))
+		d := time.Duration(float64(words) * float64(240*time.Millisecond) / (1 + float64(rate)/100))
+
+		ch := make(chan TTSChunk, 2)
+		ch <- TTSChunk{Type: "audio", Data: silentMP3(d)}
+		ch <- TTSChunk{Type: "end"}
+		close(ch)
+		return ch, nil
+	}
+}
+
+// TestDubSynthesize 测试配音时字幕按开始时间放置，过长的字幕加快语速
+func TestDubSynthesize(t *testing.T) {
+	cues := []SubCue{
+		{Index: 1, Start: 0, End: 800 * time.Millisecond, Text: "one two"},
+		{Index: 2, Start: time.Second, End: 2 * time.Second, Text: "<i>a b c d e f</i>"},
+		{Index: 3, Start: 2 * time.Second, End: 2500 * time.Millisecond, Text: "x y z w"},
+	}
+	var rates []string
+	d := NewDub(cues, "en-US-AvaNeural")
+	d.stream = fakeRateStream(&rates)
+
+	var audio bytes.Buffer
+	report, err := d.Synthesize(context.Background(), &audio)
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if len(report.Cues) != 3 {
+		t.Fatalf("got %d cues, want 3: %+v", len(report.Cues), report.Cues)
+	}
+
+	// 第一条字幕放得下，不加快
+	if got := report.Cues[0]; got.Rate != "+0%" || got.Overrun != 0 || got.Slot != 1000 {
+		t.Errorf("cue 1 = %+v, want rate +0%%, slot 1000 and no overrun", got)
+	}
+	// 第二条 1440ms 的语音要放进 1s，加快后放得下
+	if got := report.Cues[1]; got.Rate == "+0%" || got.Overrun != 0 || got.Duration > 1000 || got.Text != "a b c d e f" {
+		t.Errorf("cue 2 = %+v, want a faster rate that fits", got)
+	}
+	// 第三条超过 MaxRate 也放不下
+	if got := report.Cues[2]; got.Rate != "+50%" || got.Overrun == 0 {
+		t.Errorf("cue 3 = %+v, want rate +50%% and an overrun", got)
+	}
+	if overruns := report.Overruns(); len(overruns) != 1 || overruns[0].Index != 3 {
+		t.Errorf("Overruns() = %+v, want cue 3", overruns)
+	}
+
+	// 第三条字幕在 2s 开始，音频长度与报告一致
+	if got := report.Cues[2].Start + report.Cues[2].Duration; report.Length < got-24 || report.Length > got+24 {
+		t.Errorf("Length = %d, want about %d", report.Length, got)
+	}
+	if got, want := mp3Duration(audio.Bytes()).Milliseconds(), report.Length; got != want {
+		t.Errorf("audio is %dms, report says %dms", got, want)
+	}
+}
+
+// TestDubDelay 测试前一条字幕超时后，后面的字幕推迟开始
+func TestDubDelay(t *testing.T) {
+	cues := []SubCue{
+		{Index: 1, Start: 0, End: 500 * time.Millisecond, Text: "a b c d e f"},
+		{Index: 2, Start: 500 * time.Millisecond, End: 5 * time.Second, Text: "g"},
+	}
+	var rates []string
+	d := NewDub(cues, "en-US-AvaNeural", WithRate("+10%"))
+	d.MaxRate = 20
+	d.stream = fakeRateStream(&rates)
+
+	report, err := d.Synthesize(context.Background(), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if rates[0] != "+10%" {
+		t.Errorf("first attempt rate = %q, want the rate from Options", rates[0])
+	}
+	if got := report.Cues[0].Rate; got != "+20%" {
+		t.Errorf("cue 1 rate = %q, want +20%%", got)
+	}
+	if got := report.Cues[1]; got.Delay == 0 || got.Overrun != 0 {
+		t.Errorf("cue 2 = %+v, want a delay and no overrun", got)
+	}
+}
+
+// TestDubNeverFits 测试语音始终放不下时报告的语速与最后一次合成一致
+func TestDubNeverFits(t *testing.T) {
+	var rates []string
+	d := NewDub([]SubCue{{Index: 1, Start: 0, End: time.Second, Text: "too long"}}, "en-US-AvaNeural")
+	d.MaxRate = 200
+	// 语速只有四分之一起作用，每次都比预计的慢
+	d.stream = func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+		config := &TTSConfig{}
+		for _, opt := range opts {
+			opt(config)
+		}
+		rates = append(rates, config.Rate)
+		rate, err := parseRatePercent(config.Rate)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan TTSChunk, 2)
+		ch <- TTSChunk{Type: "audio", Data: silentMP3(time.Duration(float64(1500*time.Millisecond) / (1 + float64(rate)/400)))}
+		ch <- TTSChunk{Type: "end"}
+		close(ch)
+		return ch, nil
+	}
+
+	report, err := d.Synthesize(context.Background(), &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if len(rates) != dubFitAttempts {
+		t.Fatalf("synthesized %d times, want %d: %v", len(rates), dubFitAttempts, rates)
+	}
+	if got := report.Cues[0]; got.Rate != rates[len(rates)-1] || got.Overrun == 0 {
+		t.Errorf("cue = %+v, want rate %s of the last attempt and an overrun", got, rates[len(rates)-1])
+	}
+}
+
+// TestFitRate 测试计算放得下的语速
+func TestFitRate(t *testing.T) {
+	tests := []struct {
+		rate           int
+		duration, slot time.Duration
+		want           int
+		wantOK         bool
+	}{
+		{0, 900 * time.Millisecond, time.Second, 0, false},
+		{0, 1234 * time.Millisecond, time.Second, 29, true},
+		{10, 1150 * time.Millisecond, time.Second, 32, true},
+		{0, 1500 * time.Millisecond, time.Second, 50, true},
+		{0, 3 * time.Second, time.Second, 50, true},
+		{0, time.Second, 0, 50, true},
+		{50, 3 * time.Second, time.Second, 50, false},
+	}
+	for _, tt := range tests {
+		got, ok := fitRate(tt.rate, tt.duration, tt.slot, 50)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("fitRate(%d, %v, %v) = %d, %v, want %d, %v", tt.rate, tt.duration, tt.slot, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package edge_tts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultFitTolerance is how far the audio may be from the target duration
+// set by WithTargetDuration
+const DefaultFitTolerance = 100 * time.Millisecond
+
+// The rate WithTargetDuration may choose stays within these bounds, in
+// percent, so the speech stays natural and intelligible
+const (
+	MinFitRate = -30
+	MaxFitRate = 50
+)
+
+// fitAttempts is how many times the text is synthesized at most while
+// fitting it to a target duration
+const fitAttempts = 4
+
+// FitResult describes the synthesis chosen by WithTargetDuration
+type FitResult struct {
+	// Rate is the rate the audio was synthesized at, e.g. "+12%"
+	Rate string
+	// Duration is the length of the audio
+	Duration time.Duration
+	// Attempts is how many times the text was synthesized
+	Attempts int
+	// Fits reports whether Duration is within the tolerance of the target.
+	// It is false when the rate bounds were reached first.
+	Fits bool
+}
+
+// FitResult returns the result of fitting the text to the target duration.
+// ok is false until a Stream with WithTargetDuration has finished.
+func (c *Communicate) FitResult() (result FitResult, ok bool) {
+	if c.fit == nil {
+		return FitResult{}, false
+	}
+	return *c.fit, true
+}
+
+// fitStream synthesizes the text at different rates until it lasts the
+// target duration, then sends the chunks of the best attempt
+func (c *Communicate) fitStream(ctx context.Context) (<-chan TTSChunk, error) {
+	baseRate, err := parseRatePercent(c.config.Rate)
+	if err != nil {
+		return nil, err
+	}
+	tolerance := c.config.FitTolerance
+	if tolerance == 0 {
+		tolerance = DefaultFitTolerance
+	}
+
+	ch := make(chan TTSChunk, 100)
+	go func() {
+		defer close(ch)
+
+		synthesize := func(ctx context.Context, rate int) ([]TTSChunk, error) {
+			c.config.Rate = formatRatePercent(rate)
+			stream, err := c.stream(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var chunks []TTSChunk
+			for chunk := range stream {
+				if chunk.Type == "error" {
+					return nil, fmt.Errorf("%s", chunk.Data)
+				}
+				chunks = append(chunks, chunk)
+			}
+			return chunks, nil
+		}
+
+		chunks, result, err := fitDuration(ctx, synthesize, baseRate, c.config.TargetDuration, tolerance)
+		if err != nil {
+			ch <- TTSChunk{Type: "error", Data: []byte(err.Error())}
+			return
+		}
+		c.config.Rate = result.Rate
+		c.fit = &result
+		for _, chunk := range chunks {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// fitDuration calls synthesize with a new rate until the audio lasts
+// target within tolerance, the rate bounds are reached or it runs out of
+// attempts. It returns the chunks of the best attempt: the first one that
+// fits, else the longest one that is not too long, else the shortest.
+func fitDuration(ctx context.Context, synthesize func(context.Context, int) ([]TTSChunk, error), rate int, target, tolerance time.Duration) ([]TTSChunk, FitResult, error) {
+	var best []TTSChunk
+	var result FitResult
+	for attempt := 1; attempt <= fitAttempts; attempt++ {
+		chunks, err := synthesize(ctx, rate)
+		if err != nil {
+			return nil, FitResult{}, err
+		}
+		total, speech := measureSpeech(chunks)
+		if total == 0 {
+			return nil, FitResult{}, ErrNoAudioReceived
+		}
+
+		current := FitResult{
+			Rate:     formatRatePercent(rate),
+			Duration: total,
+			Fits:     total >= target-tolerance && total <= target+tolerance,
+		}
+		if best == nil || betterFit(current.Duration, result.Duration, target+tolerance) {
+			best, result = chunks, current
+		}
+		result.Attempts = attempt
+		if current.Fits {
+			break
+		}
+
+		// Only the speech scales with the rate, not the silence around it
+		next := MaxFitRate
+		if silence := total - speech; target-silence > 0 {
+			next = int(math.Round(scaledRate(rate, speech, target-silence)))
+		}
+		next = min(max(next, MinFitRate), MaxFitRate)
+		if next == rate {
+			break
+		}
+		rate = next
+	}
+	return best, result, nil
+}
+
+// betterFit reports whether an attempt lasting d is better than one lasting
+// best, when the audio must not be longer than limit
+func betterFit(d, best, limit time.Duration) bool {
+	if (d <= limit) != (best <= limit) {
+		return d <= limit
+	}
+	if d <= limit {
+		return d > best
+	}
+	return d < best
+}
+
+// measureSpeech returns the length of the audio in chunks and the time
+// from the start of the first word to the end of the last one. Without
+// word boundaries all of the audio counts as speech.
+func measureSpeech(chunks []TTSChunk) (total, speech time.Duration) {
+	var audio []byte
+	first, last := time.Duration(-1), time.Duration(0)
+	for _, chunk := range chunks {
+		switch chunk.Type {
+		case "audio":
+			audio = append(audio, chunk.Data...)
+		case "WordBoundary":
+			start := time.Duration(chunk.Offset * 100)
+			if first < 0 {
+				first = start
+			}
+			last = max(last, start+time.Duration(chunk.Duration*100))
+		}
+	}
+	total = mp3Duration(audio)
+	if first < 0 || last <= first {
+		return total, total
+	}
+	return total, min(last-first, total)
+}
+
+// scaledRate returns the rate, in percent, at which speech that lasts
+// duration at rate would last target. The speaking time is inversely
+// proportional to 1+rate/100.
+func scaledRate(rate int, duration, target time.Duration) float64 {
+	speed := (1 + float64(rate)/100) * float64(duration) / float64(target)
+	return (speed - 1) * 100
+}
@@ -0,0 +1,97 @@
+package edge_tts
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeSynthesize 返回一个按语速合成的函数：前后各 96ms 静音，
+// 默认语速下每个单词 240ms，语速 +r% 时为 240ms/(1+r/100)
+func fakeSynthesize(words int, rates *[]int) func(context.Context, int) ([]TTSChunk, error) {
+	return func(ctx context.Context, rate int) ([]TTSChunk, error) {
+		*rates = append(*rates, rate)
+		word := time.Duration(float64(240*time.Millisecond) / (1 + float64(rate)/100))
+		silence := 96 * time.Millisecond
+
+		var chunks []TTSChunk
+		for i := 0; i < words; i++ {
+			chunks = append(chunks, TTSChunk{
+				Type:     "WordBoundary",
+				Offset:   float64((silence + time.Duration(i)*word) / 100),
+				Duration: float64(word / 100),
+				Text:     "word",
+			})
+		}
+		chunks = append(chunks, TTSChunk{Type: "audio", Data: silentMP3(2*silence + time.Duration(words)*word)})
+		return chunks, nil
+	}
+}
+
+// TestFitDuration 测试调整语速让音频达到目标时长
+func TestFitDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   time.Duration
+		wantRate string
+		wantFits bool
+	}{
+		// 10 个单词 2400ms 加 192ms 静音
+		{"already fits", 2600 * time.Millisecond, "+0%", true},
+		{"faster", 2 * time.Second, "+33%", true},
+		{"slower", 3 * time.Second, "-15%", true},
+		{"max rate", time.Second, "+50%", false},
+		{"min rate", 5 * time.Second, "-30%", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rates []int
+			chunks, result, err := fitDuration(context.Background(), fakeSynthesize(10, &rates), 0, tt.target, DefaultFitTolerance)
+			if err != nil {
+				t.Fatalf("fitDuration() error = %v", err)
+			}
+			if result.Rate != tt.wantRate || result.Fits != tt.wantFits {
+				t.Errorf("result = %+v (rates %v), want rate %s and fits %v", result, rates, tt.wantRate, tt.wantFits)
+			}
+			if result.Attempts != len(rates) {
+				t.Errorf("Attempts = %d, want %d", result.Attempts, len(rates))
+			}
+			if total, _ := measureSpeech(chunks); total != result.Duration {
+				t.Errorf("chunks last %v, Duration = %v", total, result.Duration)
+			}
+			if result.Fits && (result.Duration < tt.target-DefaultFitTolerance || result.Duration > tt.target+DefaultFitTolerance) {
+				t.Errorf("Duration = %v, want %v ± %v", result.Duration, tt.target, DefaultFitTolerance)
+			}
+		})
+	}
+}
+
+// TestFitInvalidDuration 测试负的目标时长或误差由 Stream 返回错误
+func TestFitInvalidDuration(t *testing.T) {
+	for _, opt := range []Option{WithTargetDuration(-time.Second), WithFitTolerance(-time.Millisecond)} {
+		c := NewCommunicate("Hello", "en-US-EmmaNeural", WithTargetDuration(time.Second), opt)
+		if _, err := c.Stream(context.Background()); err == nil {
+			t.Error("Stream() should reject a negative target duration or tolerance")
+		}
+	}
+}
+
+// TestBetterFit 测试选择最好的一次合成
+func TestBetterFit(t *testing.T) {
+	limit := 2 * time.Second
+	tests := []struct {
+		d, best time.Duration
+		want    bool
+	}{
+		{1900 * time.Millisecond, 1800 * time.Millisecond, true},
+		{1800 * time.Millisecond, 1900 * time.Millisecond, false},
+		{1500 * time.Millisecond, 2500 * time.Millisecond, true},
+		{2500 * time.Millisecond, 1500 * time.Millisecond, false},
+		{2200 * time.Millisecond, 2500 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		if got := betterFit(tt.d, tt.best, limit); got != tt.want {
+			t.Errorf("betterFit(%v, %v) = %v, want %v", tt.d, tt.best, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package edge_tts
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LexiconEntry maps a written word to how it should be spoken, either an
+// alias (another spelling) or a phoneme string
+type LexiconEntry struct {
+	Grapheme string
+	Alias    string
+	Phoneme  string
+	Alphabet string // phonetic alphabet of Phoneme, e.g. "ipa" or "sapi"
+}
+
+// Lexicon is a custom pronunciation dictionary. Matching words in the
+// text are rewritten into <sub alias> or <phoneme> SSML elements.
+type Lexicon struct {
+	// Locale limits the lexicon to voices of this locale ("en-US") or
+	// language ("en"); empty applies to every voice
+	Locale  string
+	Entries []LexiconEntry
+	// CaseSensitive requires graphemes to match with the same case
+	CaseSensitive bool
+	// WholeWord only matches graphemes that are not part of a longer word
+	WholeWord bool
+}
+
+// NewLexicon creates an empty case-insensitive, whole-word lexicon
+func NewLexicon(locale string) *Lexicon {
+	return &Lexicon{
+		Locale:    locale,
+		WholeWord: true,
+	}
+}
+
+// AddAlias adds an entry that speaks grapheme as alias
+func (l *Lexicon) AddAlias(grapheme, alias string) {
+	l.Entries = append(l.Entries, LexiconEntry{Grapheme: grapheme, Alias: alias})
+}
+
+// AddPhoneme adds an entry that speaks grapheme as phoneme
+func (l *Lexicon) AddPhoneme(grapheme, phoneme, alphabet string) {
+	l.Entries = append(l.Entries, LexiconEntry{Grapheme: grapheme, Phoneme: phoneme, Alphabet: alphabet})
+}
+
+// MatchesLocale reports whether the lexicon applies to locale
+func (l *Lexicon) MatchesLocale(locale string) bool {
+	if l.Locale == "" {
+		return true
+	}
+	if strings.EqualFold(l.Locale, locale) {
+		return true
+	}
+	// A language-only lexicon ("en") applies to all its regions
+	return !strings.Contains(l.Locale, "-") &&
+		strings.HasPrefix(strings.ToLower(locale), strings.ToLower(l.Locale)+"-")
+}
+
+// Apply rewrites every matching word of text. Text inside SSML tags and
+// inside <sub> and <phoneme> elements is left alone, so when several
+// lexicons are applied the first one that rewrites a word wins. Longer
+// graphemes win over shorter ones.
+func (l *Lexicon) Apply(text string) string {
+	if len(l.Entries) == 0 {
+		return text
+	}
+
+	entries := make([]LexiconEntry, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		if entry.Grapheme != "" {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return utf8.RuneCountInString(entries[i].Grapheme) > utf8.RuneCountInString(entries[j].Grapheme)
+	})
+	// Only the entries starting with the rune at an offset are tried there
+	index := make(map[rune][]LexiconEntry)
+	for _, entry := range entries {
+		first, _ := utf8.DecodeRuneInString(entry.Grapheme)
+		key := l.indexKey(first)
+		index[key] = append(index[key], entry)
+	}
+
+	return mapTextSegments(text, func(segment string) string {
+		var b strings.Builder
+		for i := 0; i < len(segment); {
+			r, size := utf8.DecodeRuneInString(segment[i:])
+			entry, n, ok := l.match(segment, i, index[l.indexKey(r)])
+			if !ok {
+				b.WriteString(segment[i : i+size])
+				i += size
+				continue
+			}
+			b.WriteString(entry.ssml(segment[i : i+n]))
+			i += n
+		}
+		return b.String()
+	})
+}
+
+// indexKey returns the key of r in the first rune index of Apply. Without
+// CaseSensitive all the case forms of a rune share the same key.
+func (l *Lexicon) indexKey(r rune) rune {
+	if l.CaseSensitive {
+		return r
+	}
+	key := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < key {
+			key = f
+		}
+	}
+	return key
+}
+
+// match finds the entry matching segment at byte offset i and returns the
+// length of the match in bytes. Without CaseSensitive the match has as
+// many runes as the grapheme, their UTF-8 lengths may differ, as with the
+// Kelvin sign "K" and "k".
+func (l *Lexicon) match(segment string, i int, entries []LexiconEntry) (LexiconEntry, int, bool) {
+	for _, entry := range entries {
+		var end int
+		if l.CaseSensitive {
+			end = i + len(entry.Grapheme)
+			if end > len(segment) || segment[i:end] != entry.Grapheme {
+				continue
+			}
+		} else {
+			end = runeIndex(segment, i, utf8.RuneCountInString(entry.Grapheme))
+			if end < 0 || !strings.EqualFold(segment[i:end], entry.Grapheme) {
+				continue
+			}
+		}
+		if l.WholeWord && !isWordBoundary(segment, i, end) {
+			continue
+		}
+		return entry, end - i, true
+	}
+	return LexiconEntry{}, 0, false
+}
+
+// ssml returns the SSML element that speaks word using the entry
+func (e LexiconEntry) ssml(word string) string {
+	if e.Phoneme != "" {
+		alphabet := e.Alphabet
+		if alphabet == "" {
+			alphabet = "ipa"
+		}
+		return fmt.Sprintf("<phoneme alphabet='%s' ph='%s'>%s</phoneme>",
+			escapeAttr(alphabet), escapeAttr(e.Phoneme), escapeAttr(word))
+	}
+	return fmt.Sprintf("<sub alias='%s'>%s</sub>", escapeAttr(e.Alias), escapeAttr(word))
+}
+
+// isWordBoundary reports whether s[start:end] is not glued to other word
+// characters. CJK characters are not separated by spaces, so they are
+// never treated as part of a longer word.
+func isWordBoundary(s string, start, end int) bool {
+	if start > 0 {
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		first, _ := utf8.DecodeRuneInString(s[start:])
+		if isWordRune(before) && isWordRune(first) {
+			return false
+		}
+	}
+	if end < len(s) {
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		last, _ := utf8.DecodeLastRuneInString(s[:end])
+		if isWordRune(after) && isWordRune(last) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune reports whether r is part of a space-separated word
+func isWordRune(r rune) bool {
+	if isCJK(r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// isCJK reports whether r belongs to a script written without spaces
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// mapTextSegments applies f to the parts of s outside of <...> tags. Text
+// inside <sub> and <phoneme> elements already has its pronunciation, so it
+// is left alone too.
+func mapTextSegments(s string, f func(string) string) string {
+	var b strings.Builder
+	// depth counts the open <sub> and <phoneme> elements
+	depth := 0
+	for len(s) > 0 {
+		open := strings.IndexByte(s, '<')
+		if open < 0 {
+			open = len(s)
+		}
+		if open > 0 {
+			if depth > 0 {
+				b.WriteString(s[:open])
+			} else {
+				b.WriteString(f(s[:open]))
+			}
+		}
+		if open == len(s) {
+			break
+		}
+		end := strings.IndexByte(s[open:], '>')
+		if end < 0 {
+			// Unterminated tag, keep the rest as it is
+			b.WriteString(s[open:])
+			break
+		}
+		tag := s[open : open+end+1]
+		if isPronunciationTag(tag) {
+			switch {
+			case strings.HasPrefix(tag, "</"):
+				if depth > 0 {
+					depth--
+				}
+			case !strings.HasSuffix(tag, "/>"):
+				depth++
+			}
+		}
+		b.WriteString(tag)
+		s = s[open+end+1:]
+	}
+	return b.String()
+}
+
+// isPronunciationTag reports whether tag opens or closes a <sub> or
+// <phoneme> element
+func isPronunciationTag(tag string) bool {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "<"), "/")
+	if i := strings.IndexFunc(name, func(r rune) bool { return unicode.IsSpace(r) || r == '/' || r == '>' }); i >= 0 {
+		name = name[:i]
+	}
+	return name == "sub" || name == "phoneme"
+}
+
+// escapeAttr escapes s for use in a single-quoted XML attribute
+func escapeAttr(s string) string {
+	return attrReplacer.Replace(s)
+}
+
+var attrReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"'", "&apos;",
+	"\"", "&quot;",
+)
+
+// plsLexicon is the document element of a W3C PLS file
+type plsLexicon struct {
+	Alphabet string `xml:"alphabet,attr"`
+	Lang     string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Lexemes  []struct {
+		Graphemes []string `xml:"grapheme"`
+		Phonemes  []struct {
+			Alphabet string `xml:"alphabet,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"phoneme"`
+		Aliases []string `xml:"alias"`
+	} `xml:"lexeme"`
+}
+
+// ParsePLS parses a W3C Pronunciation Lexicon Specification document.
+// The lexicon locale is taken from its xml:lang attribute.
+func ParsePLS(r io.Reader) (*Lexicon, error) {
+	var doc plsLexicon
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse PLS failed: %w", err)
+	}
+
+	lexicon := NewLexicon(doc.Lang)
+	for _, lexeme := range doc.Lexemes {
+		for _, grapheme := range lexeme.Graphemes {
+			grapheme = strings.TrimSpace(grapheme)
+			switch {
+			case len(lexeme.Phonemes) > 0:
+				alphabet := lexeme.Phonemes[0].Alphabet
+				if alphabet == "" {
+					alphabet = doc.Alphabet
+				}
+				lexicon.AddPhoneme(grapheme, strings.TrimSpace(lexeme.Phonemes[0].Value), alphabet)
+			case len(lexeme.Aliases) > 0:
+				lexicon.AddAlias(grapheme, strings.TrimSpace(lexeme.Aliases[0]))
+			}
+		}
+	}
+	return lexicon, nil
+}
+
+// ParseLexiconTSV parses a tab-separated lexicon. Each line is either
+// "grapheme<TAB>alias" or "grapheme<TAB>phoneme<TAB>alphabet".
+// Blank lines and lines starting with '#' are ignored.
+func ParseLexiconTSV(r io.Reader) (*Lexicon, error) {
+	lexicon := NewLexicon("")
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		switch len(fields) {
+		case 2:
+			lexicon.AddAlias(strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]))
+		case 3:
+			lexicon.AddPhoneme(strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2]))
+		default:
+			return nil, fmt.Errorf("line %d: expected 2 or 3 tab-separated fields, got %d", lineNo, len(fields))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lexicon, nil
+}
+
+// LoadLexicon reads a lexicon file, .pls and .xml files are parsed as PLS
+// and everything else as TSV
+func LoadLexicon(path string) (*Lexicon, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pls", ".xml":
+		return ParsePLS(f)
+	default:
+		return ParseLexiconTSV(f)
+	}
+}
@@ -0,0 +1,201 @@
+package edge_tts
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// TestLexiconApply 测试词典替换
+func TestLexiconApply(t *testing.T) {
+	lexicon := NewLexicon("")
+	lexicon.AddAlias("SQL", "sequel")
+	lexicon.AddAlias("SQLite", "S Q L ite")
+	lexicon.AddPhoneme("Nginx", "ˈɛndʒɪnˈɛks", "")
+	lexicon.AddAlias("字节", "字节跳动")
+	lexicon.AddAlias("ok", "okay")
+
+	tests := []struct {
+		name          string
+		text          string
+		caseSensitive bool
+		wholeWord     bool
+		want          string
+	}{
+		{
+			name:      "整词匹配",
+			text:      "SQL and sql, not MySQL.",
+			wholeWord: true,
+			want:      "<sub alias='sequel'>SQL</sub> and <sub alias='sequel'>sql</sub>, not MySQL.",
+		},
+		{
+			name:          "区分大小写",
+			text:          "SQL and sql",
+			caseSensitive: true,
+			wholeWord:     true,
+			want:          "<sub alias='sequel'>SQL</sub> and sql",
+		},
+		{
+			name: "部分匹配",
+			text: "MySQL",
+			want: "My<sub alias='sequel'>SQL</sub>",
+		},
+		{
+			name:      "最长匹配优先",
+			text:      "SQLite behind Nginx",
+			wholeWord: true,
+			want:      "<sub alias='S Q L ite'>SQLite</sub> behind <phoneme alphabet='ipa' ph='ˈɛndʒɪnˈɛks'>Nginx</phoneme>",
+		},
+		{
+			name:      "中文",
+			text:      "欢迎来到字节。",
+			wholeWord: true,
+			want:      "欢迎来到<sub alias='字节跳动'>字节</sub>。",
+		},
+		{
+			// "ſ" 和开尔文符号 "K" 与对应的 ASCII 字母 UTF-8 长度不同
+			name:      "不同长度的大小写",
+			text:      "ſQL is O\u212A",
+			wholeWord: true,
+			want:      "<sub alias='sequel'>ſQL</sub> is <sub alias='okay'>O\u212A</sub>",
+		},
+		{
+			name:      "跳过标签",
+			text:      "<lang xml:lang='SQL'>SQL</lang>",
+			wholeWord: true,
+			want:      "<lang xml:lang='SQL'><sub alias='sequel'>SQL</sub></lang>",
+		},
+		{
+			name:      "跳过已有读法",
+			text:      "<sub alias='S Q L'>SQL</sub> <phoneme ph='x'>Nginx SQL</phoneme> SQL",
+			wholeWord: true,
+			want:      "<sub alias='S Q L'>SQL</sub> <phoneme ph='x'>Nginx SQL</phoneme> <sub alias='sequel'>SQL</sub>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lexicon.CaseSensitive = tt.caseSensitive
+			lexicon.WholeWord = tt.wholeWord
+			if got := lexicon.Apply(tt.text); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseLexicon 测试解析 PLS 和 TSV 词典
+func TestParseLexicon(t *testing.T) {
+	pls := `<?xml version="1.0" encoding="UTF-8"?>
+<lexicon version="1.0" xmlns="http://www.w3.org/2005/01/pronunciation-lexicon"
+      alphabet="ipa" xml:lang="en-US">
+  <lexeme>
+    <grapheme>Sepia</grapheme>
+    <grapheme>sepia</grapheme>
+    <phoneme>ˈsiːpiə</phoneme>
+  </lexeme>
+  <lexeme>
+    <grapheme>BTW</grapheme>
+    <alias>By the way</alias>
+  </lexeme>
+</lexicon>`
+	lexicon, err := ParsePLS(strings.NewReader(pls))
+	if err != nil {
+		t.Fatalf("ParsePLS() error = %v", err)
+	}
+	if lexicon.Locale != "en-US" {
+		t.Errorf("Locale = %q, want en-US", lexicon.Locale)
+	}
+	want := []LexiconEntry{
+		{Grapheme: "Sepia", Phoneme: "ˈsiːpiə", Alphabet: "ipa"},
+		{Grapheme: "sepia", Phoneme: "ˈsiːpiə", Alphabet: "ipa"},
+		{Grapheme: "BTW", Alias: "By the way"},
+	}
+	if len(lexicon.Entries) != len(want) {
+		t.Fatalf("Entries = %+v", lexicon.Entries)
+	}
+	for i := range want {
+		if lexicon.Entries[i] != want[i] {
+			t.Errorf("Entries[%d] = %+v, want %+v", i, lexicon.Entries[i], want[i])
+		}
+	}
+
+	tsv := "# product names\nK8s\tKubernetes\n\ntomato\ttəˈmɑːtoʊ\tipa\n"
+	lexicon, err = ParseLexiconTSV(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatalf("ParseLexiconTSV() error = %v", err)
+	}
+	want = []LexiconEntry{
+		{Grapheme: "K8s", Alias: "Kubernetes"},
+		{Grapheme: "tomato", Phoneme: "təˈmɑːtoʊ", Alphabet: "ipa"},
+	}
+	if len(lexicon.Entries) != len(want) {
+		t.Fatalf("Entries = %+v", lexicon.Entries)
+	}
+	for i := range want {
+		if lexicon.Entries[i] != want[i] {
+			t.Errorf("Entries[%d] = %+v, want %+v", i, lexicon.Entries[i], want[i])
+		}
+	}
+
+	if _, err := ParseLexiconTSV(strings.NewReader("only-one-field\n")); err == nil {
+		t.Error("ParseLexiconTSV() should reject a line without a tab")
+	}
+}
+
+// TestLexiconLocale 测试按语言选择词典
+func TestLexiconLocale(t *testing.T) {
+	en := NewLexicon("en")
+	en.AddAlias("GIF", "jif")
+	zh := NewLexicon("zh-CN")
+	zh.AddAlias("GIF", "动图")
+
+	c := NewCommunicate("GIF", "en-GB-SoniaNeural", WithLexicon(en, zh))
+	if got := c.ssmlText(); got != "<sub alias='jif'>GIF</sub>" {
+		t.Errorf("ssmlText() = %q", got)
+	}
+
+	c = NewCommunicate("GIF", "zh-CN-XiaoxiaoNeural", WithLexicon(en, zh))
+	if got := c.ssmlText(); got != "<sub alias='动图'>GIF</sub>" {
+		t.Errorf("ssmlText() = %q", got)
+	}
+}
+
+// TestLexiconOrder 测试多个词典不会嵌套改写同一个词，并且词典在规范化之前使用
+func TestLexiconOrder(t *testing.T) {
+	first := NewLexicon("")
+	first.AddAlias("SQL", "sequel")
+	first.AddAlias("Dr.", "Drive")
+	second := NewLexicon("")
+	second.AddPhoneme("SQL", "ˈɛs kjuː ˈɛl", "ipa")
+	second.AddAlias("sequel", "never")
+
+	c := NewCommunicate("Take SQL to 5 Elm Dr.", "en-US-AvaNeural", WithLexicon(first, second), WithNormalization())
+	want := "Take <sub alias='sequel'>SQL</sub> to five Elm <sub alias='Drive'>Dr.</sub>"
+	if got := c.ssmlText(); got != want {
+		t.Errorf("ssmlText() = %q, want %q", got, want)
+	}
+}
+
+// TestLexiconEscape 测试词典改写的单词会被转义，生成的 SSML 仍然合法
+func TestLexiconEscape(t *testing.T) {
+	lexicon := NewLexicon("")
+	lexicon.AddAlias("AT&T", "A T and T")
+	lexicon.AddPhoneme("R&D", "ɑːr ən diː", "ipa")
+
+	got := lexicon.Apply("Call AT&T about R&D.")
+	want := "Call <sub alias='A T and T'>AT&amp;T</sub> about <phoneme alphabet='ipa' ph='ɑːr ən diː'>R&amp;D</phoneme>."
+	if got != want {
+		t.Errorf("Apply() = %q, want %q", got, want)
+	}
+
+	entry := LexiconEntry{Grapheme: "a<b", Alias: "a less than b"}
+	if got := entry.ssml("a<b"); got != "<sub alias='a less than b'>a&lt;b</sub>" {
+		t.Errorf("ssml() = %q", got)
+	}
+	for _, ssml := range []string{want, entry.ssml("a<b")} {
+		if err := xml.Unmarshal([]byte("<speak>"+ssml+"</speak>"), new(struct{})); err != nil {
+			t.Errorf("%q is not valid XML: %v", ssml, err)
+		}
+	}
+}
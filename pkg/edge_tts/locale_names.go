@@ -0,0 +1,406 @@
+package edge_tts
+
+import "strings"
+
+// LocaleDisplayName returns the English name of a locale, e.g.
+// "German (Austria)" for "de-AT" and "Chinese (China, Liaoning)" for
+// "zh-CN-liaoning". Unknown languages and regions keep their codes.
+func LocaleDisplayName(locale string) string {
+	language, script, region, variant := splitLocale(locale)
+	name := language
+	if names, ok := languageNames[language]; ok {
+		name = names[0]
+	}
+
+	var details []string
+	if script != "" {
+		details = append(details, nameOr(scriptNames, script))
+	}
+	if region != "" {
+		details = append(details, nameOr(regionNames, region))
+	}
+	if variant != "" {
+		details = append(details, nameOr(variantNames, variant))
+	}
+	if len(details) == 0 {
+		return name
+	}
+	return name + " (" + strings.Join(details, ", ") + ")"
+}
+
+// LocaleNativeName returns the name of a locale in its own language, e.g.
+// "Deutsch (Österreich)" for "de-AT", or of a bare language such as "de".
+// Locales outside the voice list fall back to LocaleDisplayName.
+func LocaleNativeName(locale string) string {
+	for key, name := range nativeLocaleNames {
+		if strings.EqualFold(key, locale) {
+			return name
+		}
+	}
+	language, _, _, _ := splitLocale(locale)
+	if names, ok := languageNames[language]; ok && !strings.Contains(locale, "-") {
+		return names[1]
+	}
+	return LocaleDisplayName(locale)
+}
+
+// nameOr returns names[code], or code when it is not known
+func nameOr(names map[string]string, code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// languageNames are the English and native names of the languages of the
+// voice list
+var languageNames = map[string][2]string{
+	"af":  {"Afrikaans", "Afrikaans"},
+	"am":  {"Amharic", "አማርኛ"},
+	"ar":  {"Arabic", "العربية"},
+	"az":  {"Azerbaijani", "azərbaycan"},
+	"bg":  {"Bulgarian", "български"},
+	"bn":  {"Bangla", "বাংলা"},
+	"bs":  {"Bosnian", "bosanski"},
+	"ca":  {"Catalan", "català"},
+	"cs":  {"Czech", "čeština"},
+	"cy":  {"Welsh", "Cymraeg"},
+	"da":  {"Danish", "dansk"},
+	"de":  {"German", "Deutsch"},
+	"el":  {"Greek", "Ελληνικά"},
+	"en":  {"English", "English"},
+	"es":  {"Spanish", "español"},
+	"et":  {"Estonian", "eesti"},
+	"fa":  {"Persian", "فارسی"},
+	"fi":  {"Finnish", "suomi"},
+	"fil": {"Filipino", "Filipino"},
+	"fr":  {"French", "français"},
+	"ga":  {"Irish", "Gaeilge"},
+	"gl":  {"Galician", "galego"},
+	"gu":  {"Gujarati", "ગુજરાતી"},
+	"he":  {"Hebrew", "עברית"},
+	"hi":  {"Hindi", "हिन्दी"},
+	"hr":  {"Croatian", "hrvatski"},
+	"hu":  {"Hungarian", "magyar"},
+	"id":  {"Indonesian", "Bahasa Indonesia"},
+	"is":  {"Icelandic", "íslenska"},
+	"it":  {"Italian", "italiano"},
+	"iu":  {"Inuktitut", "ᐃᓄᒃᑎᑐᑦ"},
+	"ja":  {"Japanese", "日本語"},
+	"jv":  {"Javanese", "Jawa"},
+	"ka":  {"Georgian", "ქართული"},
+	"kk":  {"Kazakh", "қазақ тілі"},
+	"km":  {"Khmer", "ខ្មែរ"},
+	"kn":  {"Kannada", "ಕನ್ನಡ"},
+	"ko":  {"Korean", "한국어"},
+	"lo":  {"Lao", "ລາວ"},
+	"lt":  {"Lithuanian", "lietuvių"},
+	"lv":  {"Latvian", "latviešu"},
+	"mk":  {"Macedonian", "македонски"},
+	"ml":  {"Malayalam", "മലയാളം"},
+	"mn":  {"Mongolian", "монгол"},
+	"mr":  {"Marathi", "मराठी"},
+	"ms":  {"Malay", "Melayu"},
+	"mt":  {"Maltese", "Malti"},
+	"my":  {"Burmese", "မြန်မာ"},
+	"nb":  {"Norwegian Bokmål", "norsk bokmål"},
+	"ne":  {"Nepali", "नेपाली"},
+	"nl":  {"Dutch", "Nederlands"},
+	"pl":  {"Polish", "polski"},
+	"ps":  {"Pashto", "پښتو"},
+	"pt":  {"Portuguese", "português"},
+	"ro":  {"Romanian", "română"},
+	"ru":  {"Russian", "русский"},
+	"si":  {"Sinhala", "සිංහල"},
+	"sk":  {"Slovak", "slovenčina"},
+	"sl":  {"Slovenian", "slovenščina"},
+	"so":  {"Somali", "Soomaali"},
+	"sq":  {"Albanian", "shqip"},
+	"sr":  {"Serbian", "српски"},
+	"su":  {"Sundanese", "Basa Sunda"},
+	"sv":  {"Swedish", "svenska"},
+	"sw":  {"Swahili", "Kiswahili"},
+	"ta":  {"Tamil", "தமிழ்"},
+	"te":  {"Telugu", "తెలుగు"},
+	"th":  {"Thai", "ไทย"},
+	"tr":  {"Turkish", "Türkçe"},
+	"uk":  {"Ukrainian", "українська"},
+	"ur":  {"Urdu", "اردو"},
+	"uz":  {"Uzbek", "oʻzbek"},
+	"vi":  {"Vietnamese", "Tiếng Việt"},
+	"zh":  {"Chinese", "中文"},
+	"zu":  {"Zulu", "isiZulu"},
+}
+
+// regionNames are the English names of the regions of the voice list
+var regionNames = map[string]string{
+	"AE": "United Arab Emirates",
+	"AF": "Afghanistan",
+	"AL": "Albania",
+	"AR": "Argentina",
+	"AT": "Austria",
+	"AU": "Australia",
+	"AZ": "Azerbaijan",
+	"BA": "Bosnia & Herzegovina",
+	"BD": "Bangladesh",
+	"BE": "Belgium",
+	"BG": "Bulgaria",
+	"BH": "Bahrain",
+	"BO": "Bolivia",
+	"BR": "Brazil",
+	"CA": "Canada",
+	"CH": "Switzerland",
+	"CL": "Chile",
+	"CN": "China",
+	"CO": "Colombia",
+	"CR": "Costa Rica",
+	"CU": "Cuba",
+	"CZ": "Czechia",
+	"DE": "Germany",
+	"DK": "Denmark",
+	"DO": "Dominican Republic",
+	"DZ": "Algeria",
+	"EC": "Ecuador",
+	"EE": "Estonia",
+	"EG": "Egypt",
+	"ES": "Spain",
+	"ET": "Ethiopia",
+	"FI": "Finland",
+	"FR": "France",
+	"GB": "United Kingdom",
+	"GE": "Georgia",
+	"GQ": "Equatorial Guinea",
+	"GR": "Greece",
+	"GT": "Guatemala",
+	"HK": "Hong Kong",
+	"HN": "Honduras",
+	"HR": "Croatia",
+	"HU": "Hungary",
+	"ID": "Indonesia",
+	"IE": "Ireland",
+	"IL": "Israel",
+	"IN": "India",
+	"IQ": "Iraq",
+	"IR": "Iran",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JO": "Jordan",
+	"JP": "Japan",
+	"KE": "Kenya",
+	"KH": "Cambodia",
+	"KR": "South Korea",
+	"KW": "Kuwait",
+	"KZ": "Kazakhstan",
+	"LA": "Laos",
+	"LB": "Lebanon",
+	"LK": "Sri Lanka",
+	"LT": "Lithuania",
+	"LV": "Latvia",
+	"LY": "Libya",
+	"MA": "Morocco",
+	"MK": "North Macedonia",
+	"MM": "Myanmar",
+	"MN": "Mongolia",
+	"MT": "Malta",
+	"MX": "Mexico",
+	"MY": "Malaysia",
+	"NG": "Nigeria",
+	"NI": "Nicaragua",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NP": "Nepal",
+	"NZ": "New Zealand",
+	"OM": "Oman",
+	"PA": "Panama",
+	"PE": "Peru",
+	"PH": "Philippines",
+	"PK": "Pakistan",
+	"PL": "Poland",
+	"PR": "Puerto Rico",
+	"PT": "Portugal",
+	"PY": "Paraguay",
+	"QA": "Qatar",
+	"RO": "Romania",
+	"RS": "Serbia",
+	"RU": "Russia",
+	"SA": "Saudi Arabia",
+	"SE": "Sweden",
+	"SG": "Singapore",
+	"SI": "Slovenia",
+	"SK": "Slovakia",
+	"SO": "Somalia",
+	"SV": "El Salvador",
+	"SY": "Syria",
+	"TH": "Thailand",
+	"TN": "Tunisia",
+	"TR": "Türkiye",
+	"TW": "Taiwan",
+	"TZ": "Tanzania",
+	"UA": "Ukraine",
+	"US": "United States",
+	"UY": "Uruguay",
+	"UZ": "Uzbekistan",
+	"VE": "Venezuela",
+	"VN": "Vietnam",
+	"YE": "Yemen",
+	"ZA": "South Africa",
+}
+
+// scriptNames are the English names of the scripts of the voice list
+var scriptNames = map[string]string{
+	"Cans": "Syllabics",
+	"Latn": "Latin",
+}
+
+// variantNames are the English names of the dialects of the voice list
+var variantNames = map[string]string{
+	"liaoning": "Liaoning",
+	"shaanxi":  "Shaanxi",
+}
+
+// nativeLocaleNames are the names of the locales of the voice list in their
+// own language
+var nativeLocaleNames = map[string]string{
+	"af-ZA":          "Afrikaans (Suid-Afrika)",
+	"am-ET":          "አማርኛ (ኢትዮጵያ)",
+	"ar-AE":          "العربية (الإمارات العربية المتحدة)",
+	"ar-BH":          "العربية (البحرين)",
+	"ar-DZ":          "العربية (الجزائر)",
+	"ar-EG":          "العربية (مصر)",
+	"ar-IQ":          "العربية (العراق)",
+	"ar-JO":          "العربية (الأردن)",
+	"ar-KW":          "العربية (الكويت)",
+	"ar-LB":          "العربية (لبنان)",
+	"ar-LY":          "العربية (ليبيا)",
+	"ar-MA":          "العربية (المغرب)",
+	"ar-OM":          "العربية (عُمان)",
+	"ar-QA":          "العربية (قطر)",
+	"ar-SA":          "العربية (المملكة العربية السعودية)",
+	"ar-SY":          "العربية (سوريا)",
+	"ar-TN":          "العربية (تونس)",
+	"ar-YE":          "العربية (اليمن)",
+	"az-AZ":          "azərbaycan (Azərbaycan)",
+	"bg-BG":          "български (България)",
+	"bn-BD":          "বাংলা (বাংলাদেশ)",
+	"bn-IN":          "বাংলা (ভারত)",
+	"bs-BA":          "bosanski (Bosna i Hercegovina)",
+	"ca-ES":          "català (Espanya)",
+	"cs-CZ":          "čeština (Česko)",
+	"cy-GB":          "Cymraeg (Y Deyrnas Unedig)",
+	"da-DK":          "dansk (Danmark)",
+	"de-AT":          "Deutsch (Österreich)",
+	"de-CH":          "Deutsch (Schweiz)",
+	"de-DE":          "Deutsch (Deutschland)",
+	"el-GR":          "Ελληνικά (Ελλάδα)",
+	"en-AU":          "English (Australia)",
+	"en-CA":          "English (Canada)",
+	"en-GB":          "English (United Kingdom)",
+	"en-HK":          "English (Hong Kong)",
+	"en-IE":          "English (Ireland)",
+	"en-IN":          "English (India)",
+	"en-KE":          "English (Kenya)",
+	"en-NG":          "English (Nigeria)",
+	"en-NZ":          "English (New Zealand)",
+	"en-PH":          "English (Philippines)",
+	"en-SG":          "English (Singapore)",
+	"en-TZ":          "English (Tanzania)",
+	"en-US":          "English (United States)",
+	"en-ZA":          "English (South Africa)",
+	"es-AR":          "español (Argentina)",
+	"es-BO":          "español (Bolivia)",
+	"es-CL":          "español (Chile)",
+	"es-CO":          "español (Colombia)",
+	"es-CR":          "español (Costa Rica)",
+	"es-CU":          "español (Cuba)",
+	"es-DO":          "español (República Dominicana)",
+	"es-EC":          "español (Ecuador)",
+	"es-ES":          "español (España)",
+	"es-GQ":          "español (Guinea Ecuatorial)",
+	"es-GT":          "español (Guatemala)",
+	"es-HN":          "español (Honduras)",
+	"es-MX":          "español (México)",
+	"es-NI":          "español (Nicaragua)",
+	"es-PA":          "español (Panamá)",
+	"es-PE":          "español (Perú)",
+	"es-PR":          "español (Puerto Rico)",
+	"es-PY":          "español (Paraguay)",
+	"es-SV":          "español (El Salvador)",
+	"es-US":          "español (Estados Unidos)",
+	"es-UY":          "español (Uruguay)",
+	"es-VE":          "español (Venezuela)",
+	"et-EE":          "eesti (Eesti)",
+	"fa-IR":          "فارسی (ایران)",
+	"fi-FI":          "suomi (Suomi)",
+	"fil-PH":         "Filipino (Pilipinas)",
+	"fr-BE":          "français (Belgique)",
+	"fr-CA":          "français (Canada)",
+	"fr-CH":          "français (Suisse)",
+	"fr-FR":          "français (France)",
+	"ga-IE":          "Gaeilge (Éire)",
+	"gl-ES":          "galego (España)",
+	"gu-IN":          "ગુજરાતી (ભારત)",
+	"he-IL":          "עברית (ישראל)",
+	"hi-IN":          "हिन्दी (भारत)",
+	"hr-HR":          "hrvatski (Hrvatska)",
+	"hu-HU":          "magyar (Magyarország)",
+	"id-ID":          "Bahasa Indonesia (Indonesia)",
+	"is-IS":          "íslenska (Ísland)",
+	"it-IT":          "italiano (Italia)",
+	"iu-Cans-CA":     "ᐃᓄᒃᑎᑐᑦ (ᑲᓇᑕ)",
+	"iu-Latn-CA":     "Inuktitut (Kanata)",
+	"ja-JP":          "日本語 (日本)",
+	"jv-ID":          "Jawa (Indonésia)",
+	"ka-GE":          "ქართული (საქართველო)",
+	"kk-KZ":          "қазақ тілі (Қазақстан)",
+	"km-KH":          "ខ្មែរ (កម្ពុជា)",
+	"kn-IN":          "ಕನ್ನಡ (ಭಾರತ)",
+	"ko-KR":          "한국어 (대한민국)",
+	"lo-LA":          "ລາວ (ລາວ)",
+	"lt-LT":          "lietuvių (Lietuva)",
+	"lv-LV":          "latviešu (Latvija)",
+	"mk-MK":          "македонски (Северна Македонија)",
+	"ml-IN":          "മലയാളം (ഇന്ത്യ)",
+	"mn-MN":          "монгол (Монгол)",
+	"mr-IN":          "मराठी (भारत)",
+	"ms-MY":          "Melayu (Malaysia)",
+	"mt-MT":          "Malti (Malta)",
+	"my-MM":          "မြန်မာ (မြန်မာ)",
+	"nb-NO":          "norsk bokmål (Norge)",
+	"ne-NP":          "नेपाली (नेपाल)",
+	"nl-BE":          "Nederlands (België)",
+	"nl-NL":          "Nederlands (Nederland)",
+	"pl-PL":          "polski (Polska)",
+	"ps-AF":          "پښتو (افغانستان)",
+	"pt-BR":          "português (Brasil)",
+	"pt-PT":          "português (Portugal)",
+	"ro-RO":          "română (România)",
+	"ru-RU":          "русский (Россия)",
+	"si-LK":          "සිංහල (ශ්‍රී ලංකාව)",
+	"sk-SK":          "slovenčina (Slovensko)",
+	"sl-SI":          "slovenščina (Slovenija)",
+	"so-SO":          "Soomaali (Soomaaliya)",
+	"sq-AL":          "shqip (Shqipëri)",
+	"sr-RS":          "српски (Србија)",
+	"su-ID":          "Basa Sunda (Indonesia)",
+	"sv-SE":          "svenska (Sverige)",
+	"sw-KE":          "Kiswahili (Kenya)",
+	"sw-TZ":          "Kiswahili (Tanzania)",
+	"ta-IN":          "தமிழ் (இந்தியா)",
+	"ta-LK":          "தமிழ் (இலங்கை)",
+	"ta-MY":          "தமிழ் (மலேசியா)",
+	"ta-SG":          "தமிழ் (சிங்கப்பூர்)",
+	"te-IN":          "తెలుగు (భారతదేశం)",
+	"th-TH":          "ไทย (ไทย)",
+	"tr-TR":          "Türkçe (Türkiye)",
+	"uk-UA":          "українська (Україна)",
+	"ur-IN":          "اردو (بھارت)",
+	"ur-PK":          "اردو (پاکستان)",
+	"uz-UZ":          "oʻzbek (Oʻzbekiston)",
+	"vi-VN":          "Tiếng Việt (Việt Nam)",
+	"zh-CN":          "中文（中国）",
+	"zh-CN-liaoning": "中文（中国，辽宁）",
+	"zh-CN-shaanxi":  "中文（中国，陕西）",
+	"zh-HK":          "中文（香港）",
+	"zh-TW":          "中文（台灣）",
+	"zu-ZA":          "isiZulu (iNingizimu Afrika)",
+}
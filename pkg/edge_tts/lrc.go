@@ -0,0 +1,128 @@
+package edge_tts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LRCTags 是 LRC 歌词开头的 ID 标签，空字段不输出
+type LRCTags struct {
+	Title  string        // [ti:]
+	Artist string        // [ar:]
+	Album  string        // [al:]
+	Length time.Duration // [length:]
+}
+
+// LRCOption 是 GetLRC 的选项
+type LRCOption func(*lrcConfig)
+
+// lrcConfig 保存 LRC 的输出设置
+type lrcConfig struct {
+	tags  LRCTags
+	words bool
+}
+
+// WithLRCTags 设置 LRC 的 ID 标签
+func WithLRCTags(tags LRCTags) LRCOption {
+	return func(c *lrcConfig) {
+		c.tags = tags
+	}
+}
+
+// WithLRCWordTimestamps 输出增强 LRC，每个词前都有 <mm:ss.xx> 时间戳
+func WithLRCWordTimestamps() LRCOption {
+	return func(c *lrcConfig) {
+		c.words = true
+	}
+}
+
+// lrcTitleLength 是从文本生成标题时保留的最大字符数
+const lrcTitleLength = 40
+
+// lrcTitle 用合成文本的第一行生成标题，去掉 SSML 标签，过长时截断
+func lrcTitle(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(stripTags(text)), "\n")
+	line = strings.Join(strings.Fields(line), " ")
+	if utf8.RuneCountInString(line) <= lrcTitleLength {
+		return line
+	}
+	return string([]rune(line)[:lrcTitleLength]) + "…"
+}
+
+// lrcTagReplacer 去掉标签值中会结束标签的方括号
+var lrcTagReplacer = strings.NewReplacer("[", "(", "]", ")", "\n", " ")
+
+// GetLRC 生成 LRC 歌词，每个字幕片段一行，最后一个片段结束时输出一个空行清除歌词
+func (s *SubMaker) GetLRC(opts ...LRCOption) string {
+	config := &lrcConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var b strings.Builder
+	writeLRCTags(&b, config.tags)
+	var last *SubCue
+	for i, cue := range s.cues {
+		// 跳过空文本的字幕
+		if cue.Text == "" {
+			continue
+		}
+		config.writeLine(&b, cue)
+		last = &s.cues[i]
+	}
+	if last != nil {
+		writeLRCEnd(&b, last.End)
+	}
+	return b.String()
+}
+
+// writeLRCTags 写入非空的 ID 标签
+func writeLRCTags(b *strings.Builder, tags LRCTags) {
+	for _, tag := range []struct{ name, value string }{
+		{"ti", tags.Title},
+		{"ar", tags.Artist},
+		{"al", tags.Album},
+	} {
+		if tag.value != "" {
+			fmt.Fprintf(b, "[%s:%s]\n", tag.name, lrcTagReplacer.Replace(tag.value))
+		}
+	}
+	if tags.Length > 0 {
+		length := tags.Length.Round(time.Second)
+		fmt.Fprintf(b, "[length:%02d:%02d]\n", int(length.Minutes()), int(length.Seconds())%60)
+	}
+}
+
+// writeLine 写入一行歌词
+func (c *lrcConfig) writeLine(b *strings.Builder, cue SubCue) {
+	fmt.Fprintf(b, "[%s]", formatLRCDuration(cue.Start))
+	// 对话字幕以说话人名字作为前缀
+	if cue.Speaker != "" {
+		b.WriteString(cue.Speaker + ": ")
+	}
+	if c.words && len(cue.Words) > 0 {
+		for i, word := range cue.Words {
+			if i > 0 {
+				b.WriteString(cueSeparator(cue.Words[i-1].Text, word.Text))
+			}
+			fmt.Fprintf(b, "<%s>%s", formatLRCDuration(word.Start), word.Text)
+		}
+		fmt.Fprintf(b, "<%s>", formatLRCDuration(cue.End))
+	} else {
+		b.WriteString(unwrapText(cue.Text))
+	}
+	b.WriteString("\n")
+}
+
+// writeLRCEnd 写入在 end 时清除歌词的空行
+func writeLRCEnd(b *strings.Builder, end time.Duration) {
+	fmt.Fprintf(b, "[%s]\n", formatLRCDuration(end))
+}
+
+// formatLRCDuration 格式化 LRC 的时间，例如 "01:02.35"，分钟数可以超过 59
+func formatLRCDuration(d time.Duration) string {
+	cs := centiseconds(d)
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
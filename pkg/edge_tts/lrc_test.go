@@ -0,0 +1,47 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetLRC 测试生成 LRC 和增强 LRC 歌词
+func TestGetLRC(t *testing.T) {
+	sub := newTestSubMaker(t, "Fish", "and", "chips", "today")
+	if err := sub.MergeCues(3); err != nil {
+		t.Fatalf("MergeCues() error = %v", err)
+	}
+
+	want := "[ti:Fish (and) chips]\n" +
+		"[ar:en-US-AvaNeural]\n" +
+		"[length:01:05]\n" +
+		"[00:00.00]Fish and chips\n" +
+		"[00:00.72]today\n" +
+		"[00:00.92]\n"
+	got := sub.GetLRC(WithLRCTags(LRCTags{
+		Title:  "Fish [and] chips",
+		Artist: "en-US-AvaNeural",
+		Length: time.Minute + 4600*time.Millisecond,
+	}))
+	if got != want {
+		t.Errorf("GetLRC() = %q, want %q", got, want)
+	}
+
+	want = "[00:00.00]<00:00.00>Fish <00:00.24>and <00:00.48>chips<00:00.68>\n" +
+		"[00:00.72]<00:00.72>today<00:00.92>\n" +
+		"[00:00.92]\n"
+	if got := sub.GetLRC(WithLRCWordTimestamps()); got != want {
+		t.Errorf("GetLRC() enhanced = %q, want %q", got, want)
+	}
+}
+
+// TestLRCTitle 测试从合成文本生成标题
+func TestLRCTitle(t *testing.T) {
+	if got := lrcTitle("  <emphasis level='strong'>Intro</emphasis>  text\nsecond line"); got != "Intro text" {
+		t.Errorf("lrcTitle() = %q", got)
+	}
+	if got := lrcTitle(strings.Repeat("长", 50)); got != strings.Repeat("长", 40)+"…" {
+		t.Errorf("lrcTitle() = %q", got)
+	}
+}
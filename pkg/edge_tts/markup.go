@@ -0,0 +1,406 @@
+package edge_tts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InputFormat is the markup of the input text
+type InputFormat string
+
+const (
+	InputText     InputFormat = "text"
+	InputMarkdown InputFormat = "markdown"
+	InputHTML     InputFormat = "html"
+)
+
+// ParseInputFormat parses "text", "markdown" (or "md") and "html"
+func ParseInputFormat(s string) (InputFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text", "txt":
+		return InputText, nil
+	case "markdown", "md":
+		return InputMarkdown, nil
+	case "html", "htm":
+		return InputHTML, nil
+	}
+	return "", fmt.Errorf("unknown input format %q, expected text, markdown or html", s)
+}
+
+// CodeBlockMode decides what happens to code blocks
+type CodeBlockMode int
+
+const (
+	// CodeBlockSkip leaves code blocks out of the speech
+	CodeBlockSkip CodeBlockMode = iota
+	// CodeBlockAnnounce replaces code blocks with MarkupOptions.CodeAnnouncement
+	CodeBlockAnnounce
+)
+
+// MarkupOptions controls how document structure is turned into speech
+type MarkupOptions struct {
+	// HeadingBreak is the pause after a heading
+	HeadingBreak time.Duration
+	// CodeBlocks decides whether code blocks are skipped or announced
+	CodeBlocks CodeBlockMode
+	// CodeAnnouncement is spoken instead of a code block in CodeBlockAnnounce mode
+	CodeAnnouncement string
+}
+
+// DefaultMarkupOptions returns the options used by ConvertInput
+func DefaultMarkupOptions() MarkupOptions {
+	return MarkupOptions{
+		HeadingBreak:     750 * time.Millisecond,
+		CodeBlocks:       CodeBlockSkip,
+		CodeAnnouncement: "Code block.",
+	}
+}
+
+// ConvertInput turns Markdown or HTML into text for Communicate: markup
+// is removed and document structure becomes emphasis and breaks. Plain
+// text is returned unchanged.
+func ConvertInput(src string, format InputFormat, opts MarkupOptions) (string, error) {
+	switch format {
+	case InputText, "":
+		return src, nil
+	case InputMarkdown:
+		return MarkdownToSSML(src, opts), nil
+	case InputHTML:
+		return HTMLToSSML(src, opts)
+	}
+	return "", fmt.Errorf("unknown input format %q", format)
+}
+
+// markupBlockKind is the kind of a block of a document
+type markupBlockKind int
+
+const (
+	blockParagraph markupBlockKind = iota
+	blockHeading
+	blockListItem
+	blockCode
+)
+
+// markupBlock is one block of a document, text is escaped SSML
+type markupBlock struct {
+	kind markupBlockKind
+	text string
+}
+
+// renderMarkupBlocks joins blocks into SSML
+func renderMarkupBlocks(blocks []markupBlock, opts MarkupOptions) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		text := strings.Join(strings.Fields(block.text), " ")
+		switch block.kind {
+		case blockHeading:
+			if text == "" {
+				continue
+			}
+			part := "<emphasis level='strong'>" + text + "</emphasis>"
+			if opts.HeadingBreak > 0 {
+				part += fmt.Sprintf("<break time='%dms'/>", opts.HeadingBreak.Milliseconds())
+			}
+			parts = append(parts, part)
+		case blockListItem:
+			if text == "" {
+				continue
+			}
+			parts = append(parts, endSentence(text)+"<break strength='strong'/>")
+		case blockCode:
+			if opts.CodeBlocks == CodeBlockAnnounce && opts.CodeAnnouncement != "" {
+				parts = append(parts, escapeText(opts.CodeAnnouncement)+"<break strength='strong'/>")
+			}
+		default:
+			if text == "" {
+				continue
+			}
+			parts = append(parts, text+"<break strength='strong'/>")
+		}
+	}
+	return strings.TrimSuffix(strings.Join(parts, " "), "<break strength='strong'/>")
+}
+
+// endSentence adds a period to text that does not end with punctuation,
+// so list items are read as separate sentences
+func endSentence(text string) string {
+	plain := strings.TrimRightFunc(stripTags(text), unicode.IsSpace)
+	if plain == "" {
+		return text
+	}
+	last := []rune(plain)[len([]rune(plain))-1]
+	if unicode.IsPunct(last) {
+		return text
+	}
+	if isCJK(last) {
+		return text + "。"
+	}
+	return text + "."
+}
+
+// tagPattern matches an XML tag
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags removes XML tags from s
+func stripTags(s string) string {
+	return tagPattern.ReplaceAllString(s, "")
+}
+
+// escapeText escapes s for use as XML text
+func escapeText(s string) string {
+	return textReplacer.Replace(s)
+}
+
+var textReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var (
+	mdFence       = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeading     = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdSetext      = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	mdRule        = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	mdListItem    = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.*)$`)
+	mdQuote       = regexp.MustCompile(`^\s{0,3}>\s?`)
+	mdTableRule   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdReference   = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	mdCodeSpan    = regexp.MustCompile("`+([^`]+)`+")
+	mdImage       = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\](?:\([^)]*\)|\[[^\]]*\])`)
+	mdAutoLink    = regexp.MustCompile(`&lt;((?:https?|mailto):[^&]+)&gt;`)
+	mdHTMLTag     = regexp.MustCompile(`&lt;/?[A-Za-z][^&]*?/?&gt;`)
+	mdStrong      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEmphasis    = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	mdStrike      = regexp.MustCompile(`~~([^~]+)~~`)
+	mdEscape      = regexp.MustCompile(`\\([\\` + "`" + `*_{}\[\]()#+\-.!|>~])`)
+	mdPlaceholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// MarkdownToSSML converts Markdown into SSML text. Headings are
+// emphasized and followed by a pause, list items become sentences, links
+// are read as their text and code blocks are skipped or announced.
+func MarkdownToSSML(src string, opts MarkupOptions) string {
+	var blocks []markupBlock
+	var paragraph []string
+	inCode := false
+	fence := ""
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, markupBlock{kind: blockParagraph, text: markdownInline(strings.Join(paragraph, " "))})
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if inCode {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				inCode = false
+			}
+			continue
+		}
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, markupBlock{kind: blockCode})
+			inCode = true
+			fence = m[1]
+			continue
+		}
+
+		// Block quotes are read like the text they contain
+		for mdQuote.MatchString(line) {
+			line = mdQuote.ReplaceAllString(line, "")
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case mdSetext.MatchString(line) && len(paragraph) > 0:
+			// "Title\n=====" is a heading
+			text := strings.Join(paragraph, " ")
+			paragraph = nil
+			blocks = append(blocks, markupBlock{kind: blockHeading, text: markdownInline(text)})
+		case mdRule.MatchString(line):
+			flush()
+		case mdHeading.MatchString(line):
+			flush()
+			m := mdHeading.FindStringSubmatch(line)
+			blocks = append(blocks, markupBlock{kind: blockHeading, text: markdownInline(m[2])})
+		case mdReference.MatchString(line), mdTableRule.MatchString(line) && strings.Contains(line, "-"):
+			// Link reference definitions and table separators are not read
+		case strings.HasPrefix(strings.TrimSpace(line), "|"):
+			flush()
+			cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			blocks = append(blocks, markupBlock{kind: blockListItem, text: markdownInline(strings.Join(cells, ", "))})
+		case mdListItem.MatchString(line):
+			flush()
+			m := mdListItem.FindStringSubmatch(line)
+			blocks = append(blocks, markupBlock{kind: blockListItem, text: markdownInline(m[1])})
+		default:
+			if len(paragraph) == 0 && len(blocks) > 0 && blocks[len(blocks)-1].kind == blockListItem &&
+				strings.HasPrefix(line, " ") {
+				// Indented continuation of a list item
+				last := &blocks[len(blocks)-1]
+				last.text += " " + markdownInline(strings.TrimSpace(line))
+				continue
+			}
+			paragraph = append(paragraph, strings.TrimSpace(line))
+		}
+	}
+	flush()
+
+	return renderMarkupBlocks(blocks, opts)
+}
+
+// markdownInline removes inline Markdown from a line and escapes it
+func markdownInline(s string) string {
+	// Code spans are read literally, hide them from the other rules
+	var codes []string
+	s = mdCodeSpan.ReplaceAllStringFunc(s, func(m string) string {
+		codes = append(codes, mdCodeSpan.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("\x00%d\x00", len(codes)-1)
+	})
+
+	s = escapeText(s)
+	s = mdImage.ReplaceAllString(s, "$1")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdAutoLink.ReplaceAllString(s, "$1")
+	s = mdHTMLTag.ReplaceAllString(s, "")
+	s = mdStrong.ReplaceAllString(s, "<emphasis level='moderate'>$1$2</emphasis>")
+	s = mdEmphasis.ReplaceAllString(s, "$1$2")
+	s = mdStrike.ReplaceAllString(s, "$1")
+	s = mdEscape.ReplaceAllString(s, "$1")
+
+	return mdPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		var i int
+		fmt.Sscanf(mdPlaceholder.FindStringSubmatch(m)[1], "%d", &i)
+		return escapeText(codes[i])
+	})
+}
+
+// htmlSkipped are elements whose content is never spoken
+var htmlSkipped = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "canvas": true, "iframe": true,
+}
+
+// htmlBlocks are elements that end the current block
+var htmlBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true,
+	"footer": true, "main": true, "nav": true, "aside": true, "blockquote": true,
+	"br": true, "hr": true, "tr": true, "table": true, "ul": true, "ol": true,
+	"dl": true, "dt": true, "dd": true, "figure": true, "figcaption": true,
+	"body": true, "html": true,
+}
+
+// HTMLToSSML converts HTML into SSML text with the same rules as
+// MarkdownToSSML. Scripts, styles and the document head are ignored.
+func HTMLToSSML(src string, opts MarkupOptions) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(src))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var blocks []markupBlock
+	var text strings.Builder
+	kind := blockParagraph
+	skip := 0
+	inCode := false
+	emphasis := 0
+
+	// flush ends the current block, emphasis that is still open is closed
+	// and reopened in the next block
+	flush := func() {
+		reopen := strings.Repeat("<emphasis level='moderate'>", emphasis)
+		if strings.TrimSpace(stripTags(text.String())) != "" {
+			blocks = append(blocks, markupBlock{
+				kind: kind,
+				text: text.String() + strings.Repeat("</emphasis>", emphasis),
+			})
+		}
+		text.Reset()
+		text.WriteString(reopen)
+		kind = blockParagraph
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse HTML failed: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case htmlSkipped[name]:
+				skip++
+			case skip > 0 || inCode:
+			case name == "pre":
+				flush()
+				blocks = append(blocks, markupBlock{kind: blockCode})
+				inCode = true
+			case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+				flush()
+				kind = blockHeading
+			case name == "li":
+				flush()
+				kind = blockListItem
+			case name == "td" || name == "th":
+				if strings.TrimSpace(text.String()) != "" {
+					text.WriteString(", ")
+				}
+			case name == "strong" || name == "b":
+				text.WriteString("<emphasis level='moderate'>")
+				emphasis++
+			case name == "img":
+				for _, attr := range t.Attr {
+					if strings.EqualFold(attr.Name.Local, "alt") {
+						text.WriteString(escapeText(attr.Value))
+					}
+				}
+			case htmlBlocks[name]:
+				flush()
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case htmlSkipped[name]:
+				if skip > 0 {
+					skip--
+				}
+			case skip > 0:
+			case name == "pre":
+				inCode = false
+			case inCode:
+			case (name == "strong" || name == "b") && emphasis > 0:
+				text.WriteString("</emphasis>")
+				emphasis--
+			case name == "li" || name == "tr" || htmlBlocks[name] ||
+				len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+				if name == "tr" {
+					kind = blockListItem
+				}
+				flush()
+			}
+		case xml.CharData:
+			if skip == 0 && !inCode {
+				text.WriteString(escapeText(string(t)))
+			}
+		}
+	}
+	flush()
+
+	// Drop emphasis that wraps nothing, e.g. <b></b>
+	ssml := renderMarkupBlocks(blocks, opts)
+	return strings.ReplaceAll(ssml, "<emphasis level='moderate'></emphasis>", ""), nil
+}
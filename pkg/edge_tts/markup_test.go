@@ -0,0 +1,87 @@
+package edge_tts
+
+import (
+	"testing"
+)
+
+// TestMarkdownToSSML 测试 Markdown 转换
+func TestMarkdownToSSML(t *testing.T) {
+	src := "# Getting *Started*\n" +
+		"\n" +
+		"Read the [guide](https://example.com) and run `make build` **now**.\n" +
+		"It is fast & safe.\n" +
+		"\n" +
+		"- First step\n" +
+		"- Second step!\n" +
+		"\n" +
+		"```go\n" +
+		"fmt.Println(\"# not a heading\")\n" +
+		"```\n" +
+		"\n" +
+		"> Quoted <b>text</b>\n" +
+		"\n" +
+		"Done\n" +
+		"===\n"
+
+	want := "<emphasis level='strong'>Getting Started</emphasis><break time='750ms'/> " +
+		"Read the guide and run make build <emphasis level='moderate'>now</emphasis>. It is fast &amp; safe.<break strength='strong'/> " +
+		"First step.<break strength='strong'/> " +
+		"Second step!<break strength='strong'/> " +
+		"Quoted text<break strength='strong'/> " +
+		"<emphasis level='strong'>Done</emphasis><break time='750ms'/>"
+	if got := MarkdownToSSML(src, DefaultMarkupOptions()); got != want {
+		t.Errorf("MarkdownToSSML() =\n%q\nwant\n%q", got, want)
+	}
+
+	opts := DefaultMarkupOptions()
+	opts.CodeBlocks = CodeBlockAnnounce
+	opts.HeadingBreak = 0
+	want = "<emphasis level='strong'>Intro</emphasis> Code block.<break strength='strong'/> 列表项。"
+	if got := MarkdownToSSML("## Intro\n~~~\ncode\n~~~\n* 列表项", opts); got != want {
+		t.Errorf("MarkdownToSSML() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestHTMLToSSML 测试 HTML 转换
+func TestHTMLToSSML(t *testing.T) {
+	src := `<!DOCTYPE html>
+<html><head><title>Ignored</title><style>p { color: red }</style></head>
+<body>
+<h1>Welcome</h1>
+<p>Visit <a href="/docs">our docs</a> &amp; <b>enjoy<p>it</b></p>
+<ul><li>One</li><li>Two<br></li></ul>
+<pre><code>x := 1</code></pre>
+<script>alert("no")</script>
+<table><tr><th>Name</th><th>Age</th></tr><tr><td>Ann</td><td>30</td></tr></table>
+<img src="a.png" alt="A cat">
+</body></html>`
+
+	want := "<emphasis level='strong'>Welcome</emphasis><break time='750ms'/> " +
+		"Visit our docs &amp; <emphasis level='moderate'>enjoy</emphasis><break strength='strong'/> " +
+		"<emphasis level='moderate'>it</emphasis><break strength='strong'/> " +
+		"One.<break strength='strong'/> " +
+		"Two.<break strength='strong'/> " +
+		"Name, Age.<break strength='strong'/> " +
+		"Ann, 30.<break strength='strong'/> " +
+		"A cat"
+	got, err := HTMLToSSML(src, DefaultMarkupOptions())
+	if err != nil {
+		t.Fatalf("HTMLToSSML() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("HTMLToSSML() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// TestParseInputFormat 测试解析输入格式
+func TestParseInputFormat(t *testing.T) {
+	for input, want := range map[string]InputFormat{"": InputText, "md": InputMarkdown, "HTML": InputHTML} {
+		got, err := ParseInputFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseInputFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseInputFormat("docx"); err == nil {
+		t.Error("ParseInputFormat() should reject unknown formats")
+	}
+}
@@ -0,0 +1,260 @@
+package edge_tts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MergeOptions 控制 MergeCuesWith 如何把单词合并为字幕，0 表示不限制
+type MergeOptions struct {
+	// MaxWords 是每条字幕最多的词数，中日文按 WordBoundary 计数
+	MaxWords int
+	// MaxChars 是每条字幕最大的显示宽度，全角字符算两个字符
+	MaxChars int
+	// MaxDuration 是每条字幕最长的时间
+	MaxDuration time.Duration
+	// SentenceBreak 在句末标点之后开始新的字幕
+	SentenceBreak bool
+
+	// LineWidth 是每行最大的显示宽度，超过时在标点、空格或中日文字符之间换行，0 表示不换行
+	LineWidth int
+	// MaxLines 是换行后最多的行数，超出的文字留在最后一行。
+	// 没有设置 MaxChars 时，字幕最大宽度为 LineWidth * MaxLines
+	MaxLines int
+}
+
+// MergeCuesWith 按 opts 合并字幕片段并换行。中日文之间不插入空格，
+// 不同说话人的字幕不合并
+func (s *SubMaker) MergeCuesWith(opts MergeOptions) error {
+	merger, err := newCueMerger(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(s.cues) == 0 {
+		return nil
+	}
+
+	newCues := make([]SubCue, 0)
+	currentCue := s.cues[0]
+	for _, cue := range s.cues[1:] {
+		if merged, ok := merger.merge(currentCue, cue); ok {
+			currentCue = merged
+		} else {
+			newCues = append(newCues, merger.finish(currentCue))
+			currentCue = cue
+		}
+	}
+	newCues = append(newCues, merger.finish(currentCue))
+	s.cues = newCues
+	s.Renumber()
+	return nil
+}
+
+// cueMerger 按 MergeOptions 逐条合并字幕，MergeCuesWith 和 SubtitleWriter 共用
+type cueMerger struct {
+	opts     MergeOptions
+	maxChars int
+}
+
+// newCueMerger 检查 opts 并创建 cueMerger
+func newCueMerger(opts MergeOptions) (*cueMerger, error) {
+	if opts.MaxWords < 0 || opts.MaxChars < 0 || opts.MaxDuration < 0 || opts.LineWidth < 0 || opts.MaxLines < 0 {
+		return nil, fmt.Errorf("invalid merge options, limits must not be negative")
+	}
+	maxChars := opts.MaxChars
+	if maxChars == 0 && opts.LineWidth > 0 && opts.MaxLines > 0 {
+		maxChars = opts.LineWidth * opts.MaxLines
+	}
+	if opts.MaxWords == 0 && maxChars == 0 && opts.MaxDuration == 0 && !opts.SentenceBreak {
+		return nil, fmt.Errorf("invalid merge options, expected at least one limit")
+	}
+	return &cueMerger{opts: opts, maxChars: maxChars}, nil
+}
+
+// merge 返回 current 和 next 合并后的字幕，超出限制时返回 false
+func (m *cueMerger) merge(current, next SubCue) (SubCue, bool) {
+	text := joinCueText(current.Text, next.Text)
+	switch {
+	case next.Speaker != current.Speaker:
+		return current, false
+	case m.opts.SentenceBreak && endsSentence(current.Text):
+		return current, false
+	case m.opts.MaxWords > 0 && cueWordCount(current)+cueWordCount(next) > m.opts.MaxWords:
+		return current, false
+	case m.maxChars > 0 && textWidth(text) > m.maxChars:
+		return current, false
+	case m.opts.MaxDuration > 0 && next.End-current.Start > m.opts.MaxDuration:
+		return current, false
+	}
+	return SubCue{
+		Index:   current.Index,
+		Start:   current.Start,
+		End:     next.End,
+		Text:    text,
+		Speaker: current.Speaker,
+		Words:   append(current.Words[:len(current.Words):len(current.Words)], next.Words...),
+	}, true
+}
+
+// finish 在字幕合并完成后换行
+func (m *cueMerger) finish(cue SubCue) SubCue {
+	if m.opts.LineWidth > 0 {
+		cue.Text = wrapText(cue.Text, m.opts.LineWidth, m.opts.MaxLines)
+	}
+	return cue
+}
+
+// cueWordCount 返回字幕中的词数
+func cueWordCount(cue SubCue) int {
+	if len(cue.Words) > 0 {
+		return len(cue.Words)
+	}
+	return len(strings.Fields(cue.Text))
+}
+
+// isWide 判断字符是否占两个显示宽度，包括中日韩文字和全角标点
+func isWide(r rune) bool {
+	return isCJK(r) ||
+		(r >= 0x3000 && r <= 0x303F) || // 中日韩标点
+		(r >= 0xFF01 && r <= 0xFF60) || // 全角字符
+		(r >= 0xFFE0 && r <= 0xFFE6)
+}
+
+// textWidth 返回文本的显示宽度
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if isWide(r) {
+			width += 2
+		} else if r != '\n' {
+			width++
+		}
+	}
+	return width
+}
+
+// joinCueText 连接两段字幕文本
+func joinCueText(a, b string) string {
+	return a + cueSeparator(a, b) + b
+}
+
+// cueSeparator 返回两段字幕文本之间的分隔符，中日文之间和标点之前不插入空格
+func cueSeparator(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	if isWide(last) || isWide(first) || strings.ContainsRune(",.;:!?)]}%", first) {
+		return ""
+	}
+	return " "
+}
+
+// unwrapText 把换行后的字幕文本合并为一行
+func unwrapText(text string) string {
+	lines := strings.Split(text, "\n")
+	result := lines[0]
+	for _, line := range lines[1:] {
+		result = joinCueText(result, line)
+	}
+	return result
+}
+
+// endsSentence 判断文本是否以句末标点结束
+func endsSentence(text string) bool {
+	text = strings.TrimRight(text, " \"'”’）)")
+	last, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(".!?。！？…", last)
+}
+
+// isBreakPunct 判断字符之后是否适合换行
+func isBreakPunct(r rune) bool {
+	return strings.ContainsRune(",.;:!?，。；：！？、…", r)
+}
+
+// wrapText 把文本折成每行不超过 width 个显示宽度，最多 maxLines 行
+func wrapText(text string, width, maxLines int) string {
+	var lines []string
+	rest := []rune(strings.ReplaceAll(text, "\n", " "))
+	for textWidth(string(rest)) > width && (maxLines <= 0 || len(lines) < maxLines-1) {
+		cut := lineBreak(rest, width)
+		lines = append(lines, strings.TrimRightFunc(string(rest[:cut]), unicode.IsSpace))
+		rest = []rune(strings.TrimLeftFunc(string(rest[cut:]), unicode.IsSpace))
+	}
+	lines = append(lines, string(rest))
+	return strings.Join(lines, "\n")
+}
+
+// wordLineBreaks 按换行后的 cue.Text 找出每个词之前是否换行，供逐词输出
+// 的卡拉 OK 和 WebVTT 时间戳使用。一行在词中间断开时在这个词之前换行；
+// 文本与 Words 对不上时返回 nil，即不换行
+func wordLineBreaks(cue SubCue) []bool {
+	if !strings.Contains(cue.Text, "\n") || len(cue.Words) == 0 {
+		return nil
+	}
+
+	text := []rune(cue.Text)
+	breaks := make([]bool, len(cue.Words))
+	pos := 0
+	for i, word := range cue.Words {
+		for _, r := range word.Text {
+			for pos < len(text) && text[pos] != r {
+				if !unicode.IsSpace(text[pos]) {
+					return nil
+				}
+				if text[pos] == '\n' && i > 0 {
+					breaks[i] = true
+				}
+				pos++
+			}
+			if pos == len(text) {
+				return nil
+			}
+			pos++
+		}
+	}
+	return breaks
+}
+
+// lineBreak 返回第一行的长度（rune 数）。优先在后半行的标点之后换行，
+// 其次在空格或中日文字符之间，单词太长时强制换行
+func lineBreak(runes []rune, width int) int {
+	// n 是能放进一行的 rune 数
+	n, w := 0, 0
+	for n < len(runes) {
+		rw := textWidth(string(runes[n]))
+		if w+rw > width {
+			break
+		}
+		w += rw
+		n++
+	}
+	if n == 0 {
+		return 1
+	}
+
+	punct, soft := 0, 0
+	for i := 1; i <= n && i < len(runes); i++ {
+		switch {
+		case isBreakPunct(runes[i-1]):
+			punct = i
+		case unicode.IsSpace(runes[i]):
+			soft = i
+		// 中日文字符之间可以换行，但行首不能是标点
+		case (isWide(runes[i-1]) || isWide(runes[i])) && !isBreakPunct(runes[i]):
+			soft = i
+		}
+	}
+	switch {
+	case punct > 0 && punct*2 >= n:
+		return punct
+	case soft > 0 || punct > 0:
+		return max(soft, punct)
+	}
+	return n
+}
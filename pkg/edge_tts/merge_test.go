@@ -0,0 +1,128 @@
+package edge_tts
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMergeCuesCJK 测试中文字幕合并时不插入空格
+func TestMergeCuesCJK(t *testing.T) {
+	sub := newTestSubMaker(t, "今天", "天气", "很好", "Go", "语言", "is", "fun")
+	if err := sub.MergeCues(10); err != nil {
+		t.Fatalf("MergeCues() error = %v", err)
+	}
+	if got, want := sub.cues[0].Text, "今天天气很好Go语言is fun"; got != want {
+		t.Errorf("Text = %q, want %q", got, want)
+	}
+	if got, want := sub.GetVTT(WithVTTWordTimestamps()), "<c>今天</c><00:00:00.240><c>天气</c>"; !strings.Contains(got, want) {
+		t.Errorf("GetVTT() = %q, want it to contain %q", got, want)
+	}
+}
+
+// TestMergeCuesWith 测试按宽度、时间和句子合并字幕
+func TestMergeCuesWith(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		opts  MergeOptions
+		want  []string
+	}{
+		{
+			name:  "max chars counts full-width characters twice",
+			words: []string{"今天", "天气", "很好", "我们", "去", "公园"},
+			opts:  MergeOptions{MaxChars: 12},
+			want:  []string{"今天天气很好", "我们去公园"},
+		},
+		{
+			name:  "max duration",
+			words: []string{"one", "two", "three", "four"},
+			opts:  MergeOptions{MaxDuration: 500 * time.Millisecond},
+			want:  []string{"one two", "three four"},
+		},
+		{
+			name:  "sentence break",
+			words: []string{"Hi.", "How", "are", "you?", "Fine"},
+			opts:  MergeOptions{SentenceBreak: true},
+			want:  []string{"Hi.", "How are you?", "Fine"},
+		},
+		{
+			name:  "wrap at punctuation",
+			words: []string{"今天天气很好，", "我们去公园散步吧"},
+			opts:  MergeOptions{MaxWords: 10, LineWidth: 16, MaxLines: 2},
+			want:  []string{"今天天气很好，\n我们去公园散步吧"},
+		},
+		{
+			name:  "wrap at spaces",
+			words: []string{"the", "quick", "brown", "fox", "jumps", "over", "the", "lazy", "dog"},
+			opts:  MergeOptions{LineWidth: 10, MaxLines: 2},
+			want:  []string{"the quick\nbrown fox", "jumps over\nthe lazy", "dog"},
+		},
+		{
+			name:  "line width and max lines limit the cue size",
+			words: []string{"the", "quick", "brown", "fox", "jumps"},
+			opts:  MergeOptions{LineWidth: 10, MaxLines: 1},
+			want:  []string{"the quick", "brown fox", "jumps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newTestSubMaker(t, tt.words...)
+			if err := sub.MergeCuesWith(tt.opts); err != nil {
+				t.Fatalf("MergeCuesWith() error = %v", err)
+			}
+			if len(sub.cues) != len(tt.want) {
+				t.Fatalf("got %d cues %+v, want %q", len(sub.cues), sub.cues, tt.want)
+			}
+			for i, want := range tt.want {
+				if sub.cues[i].Text != want {
+					t.Errorf("cue %d = %q, want %q", i, sub.cues[i].Text, want)
+				}
+			}
+		})
+	}
+
+	if err := NewSubMaker().MergeCuesWith(MergeOptions{}); err == nil {
+		t.Error("MergeCuesWith() should require a limit")
+	}
+}
+
+// TestWrapText 测试中日文换行
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text     string
+		width    int
+		maxLines int
+		want     string
+	}{
+		{"一二三四五六七八九十", 8, 0, "一二三四\n五六七八\n九十"},
+		{"一二三、四五六七八", 10, 0, "一二三、\n四五六七八"},
+		{"ab 一二三，四", 8, 0, "ab 一二\n三，四"},
+		{"supercalifragilistic", 8, 2, "supercal\nifragilistic"},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width, tt.maxLines); got != tt.want {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+// TestDialogueSubtitleMerge 测试对话使用 WithSubtitleMerge 合并字幕
+func TestDialogueSubtitleMerge(t *testing.T) {
+	var calls []string
+	d := NewDialogue([]DialogueTurn{{Speaker: "小明", Text: "你好 世界 再见"}},
+		map[string]SpeakerVoice{"小明": {Voice: "zh-CN-YunxiNeural"}})
+	d.Options = []Option{WithSubtitleMerge(MergeOptions{MaxChars: 8})}
+	d.stream = fakeStream(&calls)
+
+	sub := NewSubMaker()
+	if err := d.Synthesize(context.Background(), io.Discard, sub); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if len(sub.cues) != 2 || sub.cues[0].Text != "你好世界" || sub.cues[1].Text != "再见" {
+		t.Errorf("cues = %+v", sub.cues)
+	}
+}
@@ -0,0 +1,130 @@
+package edge_tts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewMUID returns a new random MUID, the anonymous user id the service
+// gets as the muid cookie. Pass the same one to several clients with
+// WithMUID so they look like one session.
+func NewMUID() string {
+	return generateMUID()
+}
+
+// LoadMUID returns the MUID stored in path. When the file does not exist
+// it is created with a new MUID, so the same id is used on every run.
+func LoadMUID(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		muid := strings.TrimSpace(string(data))
+		if !isMUID(muid) {
+			return "", fmt.Errorf("invalid MUID in %s", path)
+		}
+		return muid, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		// Another process created it first
+		return LoadMUID(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	muid := NewMUID()
+	if _, err := f.WriteString(muid + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	return muid, f.Close()
+}
+
+// isMUID reports whether s looks like a MUID: 32 hexadecimal digits
+func isMUID(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithMUID sets the MUID sent as the muid cookie, instead of a random one
+// per Communicate
+func WithMUID(muid string) Option {
+	return func(c *TTSConfig) {
+		c.MUID, c.MUIDFile = muid, ""
+	}
+}
+
+// WithMUIDFile keeps the MUID in a file, see LoadMUID
+func WithMUIDFile(path string) Option {
+	return func(c *TTSConfig) {
+		c.MUID, c.MUIDFile = "", path
+	}
+}
+
+// WithCookieJar sends the cookies of jar with every connection and stores
+// the cookies the service sets in it. The MUID is added to the jar when it
+// has no muid cookie yet, otherwise the muid of the jar is sent.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *TTSConfig) {
+		c.CookieJar = jar
+	}
+}
+
+// muid returns the MUID of the options, from WithMUID, WithMUIDFile or ids
+func (c *TTSConfig) muid(ids IDGenerator) (string, error) {
+	if c.MUID != "" {
+		return c.MUID, nil
+	}
+	if c.MUIDFile != "" {
+		return LoadMUID(c.MUIDFile)
+	}
+	return ids.MUID(), nil
+}
+
+// MUID returns the MUID this client sends, unless its cookie jar already
+// held a muid cookie
+func (c *Communicate) MUID() string {
+	return c.muid
+}
+
+// addMUIDCookie adds muid to the jar for the WebSocket URL wsURL, unless the
+// jar already has a muid cookie for it
+func addMUIDCookie(jar http.CookieJar, wsURL, muid string) error {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return err
+	}
+	// Cookie jars only know HTTP URLs, the dialer asks with those too
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	for _, cookie := range jar.Cookies(u) {
+		if cookie.Name == "muid" {
+			return nil
+		}
+	}
+	jar.SetCookies(u, []*http.Cookie{{Name: "muid", Value: muid, Path: "/"}})
+	return nil
+}
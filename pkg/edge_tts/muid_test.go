@@ -0,0 +1,102 @@
+package edge_tts
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadMUID 测试 MUID 文件的创建和复用
+func TestLoadMUID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edge-tts", "muid")
+	muid, err := LoadMUID(path)
+	if err != nil {
+		t.Fatalf("LoadMUID() error = %v", err)
+	}
+	if !isMUID(muid) {
+		t.Errorf("LoadMUID() = %q, want 32 hex digits", muid)
+	}
+	if again, err := LoadMUID(path); err != nil || again != muid {
+		t.Errorf("second LoadMUID() = %q, %v, want %q", again, err, muid)
+	}
+
+	os.WriteFile(path, []byte("not a muid\n"), 0600)
+	if _, err := LoadMUID(path); err == nil {
+		t.Error("LoadMUID() with invalid file want error")
+	}
+}
+
+// TestMUIDOptions 测试 MUID 选项以后设置的为准
+func TestMUIDOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "muid")
+	os.WriteFile(path, []byte("0123456789ABCDEF0123456789ABCDEF\n"), 0600)
+
+	c := NewCommunicate("hi", "en-US-AvaNeural", WithMUID("FIRST"), WithMUIDFile(path))
+	if c.MUID() != "0123456789ABCDEF0123456789ABCDEF" {
+		t.Errorf("MUID() = %q, want the file MUID", c.MUID())
+	}
+	c = NewCommunicate("hi", "en-US-AvaNeural", WithMUIDFile(path), WithMUID("LAST"))
+	if c.MUID() != "LAST" {
+		t.Errorf("MUID() = %q, want LAST", c.MUID())
+	}
+	if a, b := NewCommunicate("hi", "en-US-AvaNeural"), NewCommunicate("hi", "en-US-AvaNeural"); a.MUID() == b.MUID() {
+		t.Error("two clients share a random MUID")
+	}
+}
+
+// streamCookie 合成一次并返回服务器收到的 Cookie
+func streamCookie(t *testing.T, c *Communicate, requests <-chan wireRequest) string {
+	t.Helper()
+	ch, err := c.Stream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for chunk := range ch {
+		if chunk.Type == "error" {
+			t.Fatalf("Stream() error = %s", chunk.Data)
+		}
+	}
+	return (<-requests).cookie
+}
+
+// TestMUIDStable 测试同一客户端重连时发送相同的 MUID
+func TestMUIDStable(t *testing.T) {
+	wsURL, requests := newWireServer(t, silentMP3(240*time.Millisecond), "hi")
+	c := NewCommunicate("hi", "en-US-AvaNeural", WithClientProfile(DefaultClientProfile()))
+	c.wsURL = wsURL
+
+	want := "muid=" + c.MUID() + ";"
+	for i := 0; i < 2; i++ {
+		if got := streamCookie(t, c, requests); got != want {
+			t.Errorf("connection %d cookie = %q, want %q", i, got, want)
+		}
+	}
+}
+
+// TestCookieJar 测试通过 cookie jar 发送 MUID
+func TestCookieJar(t *testing.T) {
+	wsURL, requests := newWireServer(t, silentMP3(240*time.Millisecond), "hi")
+	jar, _ := cookiejar.New(nil)
+	c := NewCommunicate("hi", "en-US-AvaNeural", WithClientProfile(DefaultClientProfile()), WithCookieJar(jar), WithMUID("MUID-1"))
+	c.wsURL = wsURL
+
+	if got := streamCookie(t, c, requests); got != "muid=MUID-1" {
+		t.Errorf("cookie = %q, want muid=MUID-1", got)
+	}
+
+	// jar 中已有的 muid 优先
+	u, _ := url.Parse("http" + strings.TrimPrefix(wsURL, "ws"))
+	jar.SetCookies(u, []*http.Cookie{{Name: "muid", Value: "FROM-JAR", Path: "/"}, {Name: "session", Value: "1", Path: "/"}})
+	c = NewCommunicate("hi", "en-US-AvaNeural", WithClientProfile(DefaultClientProfile()), WithCookieJar(jar), WithMUID("MUID-2"))
+	c.wsURL = wsURL
+	got := streamCookie(t, c, requests)
+	if !strings.Contains(got, "muid=FROM-JAR") || !strings.Contains(got, "session=1") || strings.Contains(got, "MUID-2") {
+		t.Errorf("cookie = %q, want the jar cookies", got)
+	}
+}
@@ -0,0 +1,181 @@
+package edge_tts
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextNormalizer rewrites raw text, such as "3/4", "10kg" or "Dr.", into
+// words the service reads consistently
+type TextNormalizer interface {
+	Normalize(text string) string
+}
+
+// NormalizerFunc adapts an ordinary function to a TextNormalizer
+type NormalizerFunc func(text string) string
+
+// Normalize calls f(text)
+func (f NormalizerFunc) Normalize(text string) string {
+	return f(text)
+}
+
+// NormalizerChain runs normalizers one after another
+type NormalizerChain []TextNormalizer
+
+// Normalize runs every normalizer of the chain in order
+func (c NormalizerChain) Normalize(text string) string {
+	for _, n := range c {
+		text = n.Normalize(text)
+	}
+	return text
+}
+
+// RegexRule replaces every match of Pattern with the result of Replace.
+// Replace receives the match followed by its submatches.
+type RegexRule struct {
+	Pattern *regexp.Regexp
+	Replace func(groups []string) string
+}
+
+// NewRegexRule creates a RegexRule from a regular expression
+func NewRegexRule(pattern string, replace func(groups []string) string) (*RegexRule, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexRule{Pattern: re, Replace: replace}, nil
+}
+
+// mustRegexRule is NewRegexRule for the built-in rules
+func mustRegexRule(pattern string, replace func(groups []string) string) *RegexRule {
+	rule, err := NewRegexRule(pattern, replace)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// Normalize replaces every match in text. The submatches are found in
+// text itself, so anchors such as $ see the text around the match.
+func (r *RegexRule) Normalize(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.Pattern.FindAllStringSubmatchIndex(text, -1) {
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(r.Replace(groups))
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+var (
+	// normalizersLock protects registeredNormalizers
+	normalizersLock sync.RWMutex
+	// registeredNormalizers holds the caller rules by lower-case locale or language
+	registeredNormalizers = map[string]NormalizerChain{}
+)
+
+// builtinNormalizers returns the built-in chain for a locale, the en-US
+// rules serve every English locale and the zh-CN rules mainland Chinese
+func builtinNormalizers(locale string) NormalizerChain {
+	locale = strings.ToLower(locale)
+	switch {
+	case locale == "en" || strings.HasPrefix(locale, "en-"):
+		return englishNormalizers
+	case locale == "zh" || locale == "zh-cn" || strings.HasPrefix(locale, "zh-cn-"):
+		return chineseNormalizers
+	}
+	return nil
+}
+
+// RegisterNormalizer adds a rule for a locale ("en-US") or a whole
+// language ("en"). Registered rules run before the built-in ones, so they
+// can claim text such as "3/4" before it is read as a fraction.
+func RegisterNormalizer(locale string, n TextNormalizer) {
+	normalizersLock.Lock()
+	defer normalizersLock.Unlock()
+	key := strings.ToLower(locale)
+	registeredNormalizers[key] = append(registeredNormalizers[key], n)
+}
+
+// NormalizerForLocale returns the registered and built-in rules for locale
+func NormalizerForLocale(locale string) NormalizerChain {
+	key := strings.ToLower(locale)
+	language, _, _ := strings.Cut(key, "-")
+
+	normalizersLock.RLock()
+	var chain NormalizerChain
+	chain = append(chain, registeredNormalizers[key]...)
+	if language != key {
+		chain = append(chain, registeredNormalizers[language]...)
+	}
+	normalizersLock.RUnlock()
+
+	return append(chain, builtinNormalizers(locale)...)
+}
+
+// splitDigits returns digits with the thousands separators removed
+func splitDigits(s string) string {
+	return strings.ReplaceAll(s, ",", "")
+}
+
+// readDigitByDigit reports whether a number should be read one digit at
+// a time, like phone numbers and codes with leading zeros
+func readDigitByDigit(digits string, maxLen int) bool {
+	return len(digits) > maxLen || (len(digits) > 1 && digits[0] == '0')
+}
+
+// numberPattern matches numbers with optional thousands separators and decimals
+var numberPattern = regexp.MustCompile(`\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`)
+
+// replaceNumbers replaces every number of text with read(number). Numbers
+// that are part of a word, as in "mp3" or "A4", or of a group of digits
+// joined by '.' or '-', as in "2.0.1" or "555-1234", are left alone. A '-'
+// is read as minus only when it does not join two words, as in "COVID-19".
+func replaceNumbers(text string, read func(string) string, minus string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range numberPattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if !isWordBoundary(text, start, end) || inDigitGroup(text, start, end) {
+			continue
+		}
+		words := read(text[start:end])
+		if start > 0 && text[start-1] == '-' {
+			before, _ := utf8.DecodeLastRuneInString(text[:start-1])
+			if start == 1 || unicode.IsSpace(before) || before == '(' {
+				start--
+				words = minus + words
+			}
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(words)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// inDigitGroup reports whether the number text[start:end] is joined to
+// other digits by '.' or '-', like the parts of a version or phone number
+func inDigitGroup(text string, start, end int) bool {
+	if start > 1 && (text[start-1] == '.' || text[start-1] == '-') && isDigit(text[start-2]) {
+		return true
+	}
+	return end+1 < len(text) && (text[end] == '.' || text[end] == '-') && isDigit(text[end+1])
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,451 @@
+package edge_tts
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	enOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+		"seventeen", "eighteen", "nineteen",
+	}
+	enTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	enScales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+	enMonths = []string{
+		"January", "February", "March", "April", "May", "June",
+		"July", "August", "September", "October", "November", "December",
+	}
+
+	// enOrdinalWords maps the last word of a cardinal to its ordinal
+	enOrdinalWords = map[string]string{
+		"one": "first", "two": "second", "three": "third", "five": "fifth",
+		"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+	}
+
+	// enUnits maps a unit symbol to its singular and plural names
+	enUnits = map[string][2]string{
+		"km/h": {"kilometer per hour", "kilometers per hour"},
+		"mph":  {"mile per hour", "miles per hour"},
+		"kg":   {"kilogram", "kilograms"},
+		"g":    {"gram", "grams"},
+		"mg":   {"milligram", "milligrams"},
+		"km":   {"kilometer", "kilometers"},
+		"m":    {"meter", "meters"},
+		"cm":   {"centimeter", "centimeters"},
+		"mm":   {"millimeter", "millimeters"},
+		"mi":   {"mile", "miles"},
+		"ft":   {"foot", "feet"},
+		"lb":   {"pound", "pounds"},
+		"lbs":  {"pound", "pounds"},
+		"oz":   {"ounce", "ounces"},
+		"l":    {"liter", "liters"},
+		"L":    {"liter", "liters"},
+		"ml":   {"milliliter", "milliliters"},
+		"mL":   {"milliliter", "milliliters"},
+		"°C":   {"degree Celsius", "degrees Celsius"},
+		"°F":   {"degree Fahrenheit", "degrees Fahrenheit"},
+		"KB":   {"kilobyte", "kilobytes"},
+		"MB":   {"megabyte", "megabytes"},
+		"GB":   {"gigabyte", "gigabytes"},
+		"TB":   {"terabyte", "terabytes"},
+		"Hz":   {"hertz", "hertz"},
+		"kHz":  {"kilohertz", "kilohertz"},
+		"MHz":  {"megahertz", "megahertz"},
+		"GHz":  {"gigahertz", "gigahertz"},
+		"ms":   {"millisecond", "milliseconds"},
+		"min":  {"minute", "minutes"},
+		"h":    {"hour", "hours"},
+		"hr":   {"hour", "hours"},
+		"hrs":  {"hour", "hours"},
+	}
+
+	// enCurrencies maps a currency symbol to its main and fractional units
+	enCurrencies = map[string][4]string{
+		"$": {"dollar", "dollars", "cent", "cents"},
+		"€": {"euro", "euros", "cent", "cents"},
+		"£": {"pound", "pounds", "penny", "pence"},
+		"¥": {"yen", "yen", "", ""},
+	}
+
+	// enAbbreviations maps abbreviations, without their period, to words.
+	// Titles are never followed by the end of a sentence.
+	enAbbreviations = map[string]string{
+		"Dr": "Doctor", "Mr": "Mister", "Mrs": "Missus", "Ms": "Miz", "Prof": "Professor",
+		"Jr": "Junior", "Sr": "Senior", "Mt": "Mount",
+		"vs": "versus", "etc": "et cetera", "approx": "approximately", "dept": "department",
+		"e.g": "for example", "i.e": "that is",
+		"Jan": "January", "Feb": "February", "Mar": "March", "Apr": "April",
+		"Aug": "August", "Sep": "September", "Sept": "September", "Oct": "October",
+		"Nov": "November", "Dec": "December",
+	}
+	enTitles = map[string]bool{"Dr": true, "Mr": true, "Mrs": true, "Ms": true, "Prof": true, "Mt": true}
+)
+
+// enCardinal returns the English words of n, e.g. "one hundred twenty-three"
+func enCardinal(n int64) string {
+	if n < 0 {
+		return "minus " + enCardinal(-n)
+	}
+	if n < 20 {
+		return enOnes[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return enTens[n/10]
+		}
+		return enTens[n/10] + "-" + enOnes[n%10]
+	}
+	if n < 1000 {
+		words := enOnes[n/100] + " hundred"
+		if n%100 != 0 {
+			words += " " + enCardinal(n%100)
+		}
+		return words
+	}
+
+	var parts []string
+	for scale := 0; n > 0; scale++ {
+		if group := n % 1000; group != 0 {
+			part := enCardinal(group)
+			if enScales[scale] != "" {
+				part += " " + enScales[scale]
+			}
+			parts = append([]string{part}, parts...)
+		}
+		n /= 1000
+	}
+	return strings.Join(parts, " ")
+}
+
+// enOrdinal returns the English ordinal words of n, e.g. "twenty-first"
+func enOrdinal(n int64) string {
+	words := enCardinal(n)
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	switch {
+	case enOrdinalWords[last] != "":
+		last = enOrdinalWords[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:cut] + last
+}
+
+// enDigits reads digits one at a time
+func enDigits(digits string) string {
+	words := make([]string, 0, len(digits))
+	for _, d := range digits {
+		words = append(words, enOnes[d-'0'])
+	}
+	return strings.Join(words, " ")
+}
+
+// enNumber reads a number such as "1,234.5"
+func enNumber(number string) string {
+	integer, fraction, hasFraction := strings.Cut(splitDigits(number), ".")
+
+	var words string
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || readDigitByDigit(integer, 15) {
+		words = enDigits(integer)
+	} else {
+		words = enCardinal(n)
+	}
+	if hasFraction {
+		words += " point " + enDigits(fraction)
+	}
+	return words
+}
+
+// enYear reads a year the way it is spoken, e.g. "twenty twenty-four"
+func enYear(year int64) string {
+	switch {
+	case year < 1000 || year >= 10000:
+		return enCardinal(year)
+	case year%1000 < 10:
+		// 2000-2009 read as a plain number
+		return enCardinal(year)
+	case year%100 == 0:
+		return enCardinal(year/100) + " hundred"
+	case year%100 < 10:
+		return enCardinal(year/100) + " oh " + enOnes[year%100]
+	}
+	return enCardinal(year/100) + " " + enCardinal(year%100)
+}
+
+// enDate reads a date as "May first, twenty twenty-four"
+func enDate(year, month, day string) (string, bool) {
+	y, _ := strconv.ParseInt(year, 10, 64)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.ParseInt(day, 10, 64)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return "", false
+	}
+	return enMonths[m-1] + " " + enOrdinal(d) + ", " + enYear(y), true
+}
+
+// enClock reads a time of day such as "10:05" as "ten oh five"
+func enClock(hour, minute, second string) (string, bool) {
+	h, _ := strconv.ParseInt(hour, 10, 64)
+	m, _ := strconv.ParseInt(minute, 10, 64)
+	if h > 23 || m > 59 {
+		return "", false
+	}
+
+	words := enCardinal(h)
+	switch {
+	case minute == "" || (m == 0 && second == ""):
+		if minute != "" {
+			words += " o'clock"
+		}
+	case m < 10:
+		words += " oh " + enOnes[m]
+	default:
+		words += " " + enCardinal(m)
+	}
+	if second != "" {
+		s, _ := strconv.ParseInt(second, 10, 64)
+		if s > 59 {
+			return "", false
+		}
+		words += " and " + enCardinal(s) + " seconds"
+	}
+	return words, true
+}
+
+// enFractionName returns the denominator word of a fraction
+func enFractionName(numerator, denominator int64) string {
+	var name string
+	switch denominator {
+	case 2:
+		if numerator != 1 {
+			return "halves"
+		}
+		return "half"
+	case 4:
+		name = "quarter"
+	default:
+		name = enOrdinal(denominator)
+	}
+	if numerator != 1 {
+		name += "s"
+	}
+	return name
+}
+
+// enSpellAddress reads the punctuation of URLs and e-mail addresses
+func enSpellAddress(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address = strings.TrimSuffix(address, "/")
+	replacer := strings.NewReplacer(
+		".", " dot ",
+		"@", " at ",
+		"/", " slash ",
+		"-", " dash ",
+		"_", " underscore ",
+		":", " colon ",
+		"?", " question mark ",
+		"=", " equals ",
+		"&", " and ",
+	)
+	return strings.Join(strings.Fields(replacer.Replace(address)), " ")
+}
+
+// enAbbreviationPattern matches a word followed by a period
+var enAbbreviationPattern = regexp.MustCompile(`\b(e\.g|i\.e|[A-Za-z]+)\.`)
+
+// enAbbreviationWords expands known abbreviations. The period is kept
+// when the abbreviation also ends the sentence.
+func enAbbreviationWords(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range enAbbreviationPattern.FindAllStringSubmatchIndex(text, -1) {
+		abbr := text[loc[2]:loc[3]]
+		rest := strings.TrimLeftFunc(text[loc[1]:], unicode.IsSpace)
+		words, ok := enAbbreviations[abbr]
+		title := enTitles[abbr]
+		if abbr == "St" {
+			words, title, ok = enStreetOrSaint(text[:loc[0]], rest)
+		}
+		if !ok {
+			continue
+		}
+
+		next, _ := utf8.DecodeRuneInString(rest)
+		if !title && (rest == "" || unicode.IsUpper(next)) {
+			words += "."
+		}
+
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(words)
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// enStreetOrSaint reads "St." as "Street" after a capitalized name, as in
+// "Main St.", and as the title "Saint" before one, as in "St. Louis". before
+// and rest are the text around it. ok is false when it is neither.
+func enStreetOrSaint(before, rest string) (words string, title, ok bool) {
+	fields := strings.Fields(before)
+	if n := len(fields); n > 1 && startsUpper(fields[n-1]) && !strings.ContainsAny(fields[n-2][len(fields[n-2])-1:], ".!?") {
+		// The word before is a name, not just the first word of a sentence
+		return "Street", false, true
+	}
+	if startsUpper(rest) {
+		return "Saint", true, true
+	}
+	return "", false, false
+}
+
+// startsUpper reports whether s starts with an upper case letter
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
+
+// englishNormalizers are the built-in en-US rules. The order matters:
+// addresses and dates are claimed before their digits are read as numbers.
+var englishNormalizers = NormalizerChain{
+	// URLs and e-mail addresses
+	mustRegexRule(`https?://[^\s<>"]*[^\s<>".,;:!?)]|www\.[^\s<>"]*[^\s<>".,;:!?)]`, func(g []string) string {
+		return enSpellAddress(g[0])
+	}),
+	mustRegexRule(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+`, func(g []string) string {
+		return enSpellAddress(g[0])
+	}),
+	// Dates: 2024-05-01 and 5/1/2024
+	mustRegexRule(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`, func(g []string) string {
+		if words, ok := enDate(g[1], g[2], g[3]); ok {
+			return words
+		}
+		return g[0]
+	}),
+	mustRegexRule(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`, func(g []string) string {
+		if words, ok := enDate(g[3], g[1], g[2]); ok {
+			return words
+		}
+		return g[0]
+	}),
+	// Times: 10:30, 10:30:15, 10:30 pm and 5pm. The period of "a.m." also
+	// ends the sentence when a capital letter or the end of text follows.
+	mustRegexRule(`\b(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?\s?([AaPp])\.?[Mm]\b(?:(\.)(\s+\p{Lu}|$)|\.)?`, func(g []string) string {
+		minute := g[2]
+		if minute == "00" && g[3] == "" {
+			// "10:00 am" is "ten a m"
+			minute = ""
+		}
+		words, ok := enClock(g[1], minute, g[3])
+		if !ok {
+			return g[0]
+		}
+		return words + " " + strings.ToLower(g[4]) + " m" + g[5] + g[6]
+	}),
+	mustRegexRule(`\b(\d{1,2}):(\d{2})(?::(\d{2}))?\b`, func(g []string) string {
+		if words, ok := enClock(g[1], g[2], g[3]); ok {
+			return words
+		}
+		return g[0]
+	}),
+	// Currency: $1,234.56, €5 and $1.5 million
+	mustRegexRule(`([$€£¥])\s?(\d{1,3}(?:,\d{3})+|\d+)(?:\.(\d+))?(?:\s(thousand|million|billion|trillion)\b)?`, func(g []string) string {
+		names := enCurrencies[g[1]]
+		integer, _ := strconv.ParseInt(splitDigits(g[2]), 10, 64)
+		if g[4] != "" {
+			// "$1.5 million" is "one point five million dollars"
+			number := g[2]
+			if g[3] != "" {
+				number += "." + g[3]
+			}
+			return enNumber(number) + " " + g[4] + " " + names[1]
+		}
+
+		if g[3] != "" && (names[2] == "" || len(g[3]) != 2) {
+			return enNumber(g[2]+"."+g[3]) + " " + names[1]
+		}
+		cents, _ := strconv.ParseInt(g[3], 10, 64)
+		if integer == 0 && cents > 0 {
+			// "$0.99" is "ninety-nine cents"
+			if cents == 1 {
+				return "one " + names[2]
+			}
+			return enCardinal(cents) + " " + names[3]
+		}
+
+		words := enNumber(g[2]) + " " + names[0]
+		if integer != 1 {
+			words = enNumber(g[2]) + " " + names[1]
+		}
+		if g[3] != "" {
+			if cents == 1 {
+				words += " and one " + names[2]
+			} else if cents > 0 {
+				words += " and " + enCardinal(cents) + " " + names[3]
+			}
+		}
+		return words
+	}),
+	// Percentages
+	mustRegexRule(`(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)\s?%`, func(g []string) string {
+		return enNumber(g[1]) + " percent"
+	}),
+	// Ordinals: 1st, 22nd, 103rd
+	mustRegexRule(`\b(\d+)(?:st|nd|rd|th)\b`, func(g []string) string {
+		n, err := strconv.ParseInt(g[1], 10, 64)
+		if err != nil {
+			return g[0]
+		}
+		return enOrdinal(n)
+	}),
+	// Decades: 1990s and '80s. A bare "30s" is more often 30 seconds.
+	mustRegexRule(`\b(\d{3}0)s\b|'([2-9]0)s\b`, func(g []string) string {
+		n, _ := strconv.ParseInt(g[1]+g[2], 10, 64)
+		words := enYear(n)
+		if strings.HasSuffix(words, "y") {
+			return strings.TrimSuffix(words, "y") + "ies"
+		}
+		return words + "s"
+	}),
+	// Proper fractions: 3/4
+	mustRegexRule(`\b(\d{1,3})/(\d{1,3})\b`, func(g []string) string {
+		numerator, _ := strconv.ParseInt(g[1], 10, 64)
+		denominator, _ := strconv.ParseInt(g[2], 10, 64)
+		if numerator == 0 || denominator < 2 || numerator >= denominator {
+			return g[0]
+		}
+		return enCardinal(numerator) + " " + enFractionName(numerator, denominator)
+	}),
+	// Units: 10kg, 5 km, 20°C. Single-letter units must follow the number
+	// without a space, so "Chapter 3 l" is not read as liters. As in
+	// replaceNumbers, '-' is a minus sign only at the start or after a space
+	// or '(', so the range "5-10kg" keeps its hyphen.
+	mustRegexRule(`((^|[\s(])-)?(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)(?:\s?(km/h|mph|kg|mg|km|cm|mm|mi|ft|lbs|lb|oz|ml|mL|°C|°F|KB|MB|GB|TB|kHz|MHz|GHz|Hz|ms|min|hrs|hr)|(g|m|l|L|h))\b`, func(g []string) string {
+		names := enUnits[g[4]+g[5]]
+		words := enNumber(g[3]) + " " + names[1]
+		if g[3] == "1" {
+			words = "one " + names[0]
+		}
+		if g[1] != "" {
+			return g[2] + "minus " + words
+		}
+		return words
+	}),
+	// "No. 5" is "number five"
+	mustRegexRule(`\bNo\.\s?(\d)`, func(g []string) string {
+		return "number " + g[1]
+	}),
+	// Abbreviations: Dr., etc., e.g.
+	NormalizerFunc(enAbbreviationWords),
+	// Everything else that is still a number
+	NormalizerFunc(func(text string) string {
+		return replaceNumbers(text, enNumber, "minus ")
+	}),
+}
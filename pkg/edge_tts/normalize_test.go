@@ -0,0 +1,133 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestEnglishNormalizers 测试内置英文规则
+func TestEnglishNormalizers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Add 3/4 cup", "Add three quarters cup"},
+		{"It weighs 10kg", "It weighs ten kilograms"},
+		{"Only 1 km left", "Only one kilometer left"},
+		{"Released on 2024-05-01.", "Released on May first, twenty twenty-four."},
+		{"Due 12/25/1999", "Due December twenty-fifth, nineteen ninety-nine"},
+		{"Dr. Smith arrived at 10:05", "Doctor Smith arrived at ten oh five"},
+		{"Meet at 5pm or 10:00 a.m.", "Meet at five p m or ten a m."},
+		{"I wake at 7 a.m. Then I eat.", "I wake at seven a m. Then I eat."},
+		{"Leave at 7 a.m. then eat", "Leave at seven a m then eat"},
+		{"Apples, pears, etc. Then more.", "Apples, pears, et cetera. Then more."},
+		{"It costs $1,234.56", "It costs one thousand two hundred thirty-four dollars and fifty-six cents"},
+		{"Only $0.99 or €1", "Only ninety-nine cents or one euro"},
+		{"A $1.5 million deal", "A one point five million dollars deal"},
+		{"Up 50% on the 21st", "Up fifty percent on the twenty-first"},
+		{"The 1990s", "The nineteen nineties"},
+		{"COVID-19 at -5 degrees", "COVID-nineteen at minus five degrees"},
+		{"It is -5°C", "It is minus five degrees Celsius"},
+		{"-5°C at night", "minus five degrees Celsius at night"},
+		{"5-10kg of rice", "five-ten kilograms of rice"},
+		{"Pi is 3.14", "Pi is three point one four"},
+		{"Call 007", "Call zero zero seven"},
+		{"Visit https://example.com/docs.", "Visit example dot com slash docs."},
+		{"Mail john.doe@example.com", "Mail john dot doe at example dot com"},
+		{"No. 5 of 1000000", "number five of one million"},
+		{"Play the mp3 on A4 paper", "Play the mp3 on A4 paper"},
+		{"Music of the '80s and '90s", "Music of the eighties and nineties"},
+		{"wait 30s please", "wait 30s please"},
+		{"I live on Main St.", "I live on Main Street."},
+		{"We flew to St. Louis", "We flew to Saint Louis"},
+		{"Visit St. Paul at 221B Baker St. today", "Visit Saint Paul at 221B Baker Street today"},
+		{"Take the st. off", "Take the st. off"},
+		{"Chapter 3 l and 5m", "Chapter three l and five meters"},
+		{"Stand 3 m apart for 2 h", "Stand three m apart for two h"},
+		{"Version 2.0.1 is out", "Version 2.0.1 is out"},
+		{"Call 555-1234 now", "Call 555-1234 now"},
+	}
+
+	chain := NormalizerForLocale("en-US")
+	for _, tt := range tests {
+		if got := chain.Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestChineseNormalizers 测试内置中文规则
+func TestChineseNormalizers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"加入3/4杯水", "加入四分之三杯水"},
+		{"重10kg", "重十公斤"},
+		{"2024-05-01发布", "二零二四年五月一日发布"},
+		{"2024年5月1日", "二零二四年五月一日"},
+		{"会议在10:05开始，14:00结束", "会议在十点零五分开始，十四点整结束"},
+		{"价格¥1,234.5", "价格一千二百三十四点五元"},
+		{"价格￥12.5元", "价格十二点五元"},
+		{"只要¥5块", "只要五块"},
+		{"第3 l桶和5m", "第三 l桶和五米"},
+		{"版本2.0.1发布", "版本2.0.1发布"},
+		{"增长50%", "增长百分之五十"},
+		{"第1名", "第一名"},
+		{"共10010人", "共一万零一十人"},
+		{"气温-5℃", "气温负五摄氏度"},
+		{"-5°C", "负五摄氏度"},
+		{"5-10kg大米", "五-十公斤大米"},
+		{"拨打13800138000", "拨打一三八零零一三八零零零"},
+		{"邮箱a@b.cn", "邮箱a艾特b点cn"},
+	}
+
+	chain := NormalizerForLocale("zh-CN")
+	for _, tt := range tests {
+		if got := chain.Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestRegisterNormalizer 测试注册自定义规则
+func TestRegisterNormalizer(t *testing.T) {
+	rule, err := NewRegexRule(`\bASAP\b`, func(g []string) string {
+		return "as soon as possible"
+	})
+	if err != nil {
+		t.Fatalf("NewRegexRule() error = %v", err)
+	}
+	RegisterNormalizer("en-GB", rule)
+	defer func() {
+		normalizersLock.Lock()
+		delete(registeredNormalizers, "en-gb")
+		normalizersLock.Unlock()
+	}()
+
+	if got := NormalizerForLocale("en-GB").Normalize("Reply ASAP with 2 items"); got != "Reply as soon as possible with two items" {
+		t.Errorf("Normalize() = %q", got)
+	}
+	if got := NormalizerForLocale("en-US").Normalize("Reply ASAP"); got != "Reply ASAP" {
+		t.Errorf("Normalize() for another locale = %q", got)
+	}
+	if got := NormalizerForLocale("fr-FR").Normalize("3 kg"); got != "3 kg" {
+		t.Errorf("Normalize() without rules = %q", got)
+	}
+}
+
+// TestCreateSSMLNormalization 测试合成前的文本规范化
+func TestCreateSSMLNormalization(t *testing.T) {
+	shout := NormalizerFunc(func(text string) string {
+		return strings.ReplaceAll(text, "fr", "FR")
+	})
+	c := NewCommunicate("10kg of fromage", "en-US-AvaNeural",
+		WithLangSpans(LangSpan{Start: 8, End: 15, Lang: "fr-FR"}),
+		WithNormalization(shout))
+
+	// 标签属性不会被规范化
+	want := "ten kilograms of <lang xml:lang='fr-FR'>FRomage</lang>"
+	if got := c.ssmlText(); got != want {
+		t.Errorf("ssmlText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,263 @@
+package edge_tts
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	zhDigitNames = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	// zhSectionUnits 是每四位一节的单位
+	zhSectionUnits = []string{"", "万", "亿", "万亿"}
+
+	// zhUnits 单位符号对应的中文读法
+	zhUnits = map[string]string{
+		"km/h": "公里每小时",
+		"kg":   "公斤",
+		"g":    "克",
+		"mg":   "毫克",
+		"km":   "公里",
+		"m":    "米",
+		"cm":   "厘米",
+		"mm":   "毫米",
+		"l":    "升",
+		"L":    "升",
+		"ml":   "毫升",
+		"mL":   "毫升",
+		"°C":   "摄氏度",
+		"℃":    "摄氏度",
+		"°F":   "华氏度",
+		"Hz":   "赫兹",
+		"kHz":  "千赫",
+		"MHz":  "兆赫",
+		"GHz":  "吉赫",
+		"ms":   "毫秒",
+		"min":  "分钟",
+		"h":    "小时",
+	}
+
+	// zhCurrencies 货币符号对应的中文读法
+	zhCurrencies = map[string]string{
+		"¥": "元",
+		"￥": "元",
+		"$": "美元",
+		"€": "欧元",
+		"£": "英镑",
+	}
+
+	// zhAbbreviations 中文文本中常见的英文缩写
+	zhAbbreviations = map[string]string{
+		"vs.": "对", "VS.": "对", "VS": "对", "vs": "对",
+		"etc.": "等等",
+		"No.":  "第",
+	}
+)
+
+// zhSection 读出 1 到 9999 之间的数字，例如 "一千零五"
+func zhSection(n int64) string {
+	units := []string{"千", "百", "十", ""}
+	divisors := []int64{1000, 100, 10, 1}
+
+	var b strings.Builder
+	zero := false
+	for i, divisor := range divisors {
+		digit := n / divisor % 10
+		if digit == 0 {
+			zero = b.Len() > 0
+			continue
+		}
+		if zero {
+			b.WriteString("零")
+			zero = false
+		}
+		b.WriteString(zhDigitNames[digit])
+		b.WriteString(units[i])
+	}
+	return b.String()
+}
+
+// zhCardinal 返回数字的中文读法，例如 "一万零一百"
+func zhCardinal(n int64) string {
+	if n == 0 {
+		return "零"
+	}
+	if n < 0 {
+		return "负" + zhCardinal(-n)
+	}
+
+	var sections []int64
+	for n > 0 {
+		sections = append(sections, n%10000)
+		n /= 10000
+	}
+
+	var b strings.Builder
+	zero := false
+	for i := len(sections) - 1; i >= 0; i-- {
+		section := sections[i]
+		if section == 0 {
+			zero = b.Len() > 0
+			continue
+		}
+		if b.Len() > 0 && (zero || section < 1000) {
+			b.WriteString("零")
+		}
+		b.WriteString(zhSection(section))
+		b.WriteString(zhSectionUnits[i])
+		zero = false
+	}
+
+	// 10 到 19 读作 "十"、"十一" 而不是 "一十"
+	words := b.String()
+	if strings.HasPrefix(words, "一十") {
+		words = strings.TrimPrefix(words, "一")
+	}
+	return words
+}
+
+// zhDigits 逐位读出数字
+func zhDigits(digits string) string {
+	var b strings.Builder
+	for _, d := range digits {
+		b.WriteString(zhDigitNames[d-'0'])
+	}
+	return b.String()
+}
+
+// zhNumber 读出 "1,234.5" 这样的数字
+func zhNumber(number string) string {
+	integer, fraction, hasFraction := strings.Cut(splitDigits(number), ".")
+
+	var words string
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || readDigitByDigit(integer, 10) {
+		words = zhDigits(integer)
+	} else {
+		words = zhCardinal(n)
+	}
+	if hasFraction {
+		words += "点" + zhDigits(fraction)
+	}
+	return words
+}
+
+// zhDate 读出日期，年份逐位读出，例如 "二零二四年五月一日"
+func zhDate(year, month, day string) (string, bool) {
+	m, _ := strconv.ParseInt(month, 10, 64)
+	d, _ := strconv.ParseInt(day, 10, 64)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return "", false
+	}
+	return zhDigits(year) + "年" + zhCardinal(m) + "月" + zhCardinal(d) + "日", true
+}
+
+// zhClock 读出时间，例如 "十点零五分"
+func zhClock(hour, minute, second string) (string, bool) {
+	h, _ := strconv.ParseInt(hour, 10, 64)
+	m, _ := strconv.ParseInt(minute, 10, 64)
+	if h > 23 || m > 59 {
+		return "", false
+	}
+
+	words := zhCardinal(h) + "点"
+	switch {
+	case m == 0 && second == "":
+		words += "整"
+	case m < 10:
+		words += "零" + zhCardinal(m) + "分"
+	default:
+		words += zhCardinal(m) + "分"
+	}
+	if second != "" {
+		s, _ := strconv.ParseInt(second, 10, 64)
+		if s > 59 {
+			return "", false
+		}
+		words += zhCardinal(s) + "秒"
+	}
+	return words, true
+}
+
+// zhSpellAddress 读出网址和邮箱中的符号
+func zhSpellAddress(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address = strings.TrimSuffix(address, "/")
+	replacer := strings.NewReplacer(
+		".", "点",
+		"@", "艾特",
+		"/", "斜杠",
+		"-", "杠",
+		"_", "下划线",
+		":", "冒号",
+	)
+	return replacer.Replace(address)
+}
+
+// chineseNormalizers 是内置的 zh-CN 规则，顺序很重要：
+// 网址和日期要在其中的数字被当作普通数字读出之前处理
+var chineseNormalizers = NormalizerChain{
+	// 网址和邮箱
+	mustRegexRule(`https?://[^\s<>"]*[^\s<>".,;:!?)，。]|www\.[^\s<>"]*[^\s<>".,;:!?)，。]`, func(g []string) string {
+		return zhSpellAddress(g[0])
+	}),
+	mustRegexRule(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+`, func(g []string) string {
+		return zhSpellAddress(g[0])
+	}),
+	// 日期：2024-05-01、2024/5/1 和 2024年5月1日
+	mustRegexRule(`(\d{4})[-/](\d{1,2})[-/](\d{1,2})`, func(g []string) string {
+		if words, ok := zhDate(g[1], g[2], g[3]); ok {
+			return words
+		}
+		return g[0]
+	}),
+	mustRegexRule(`(\d{4})年`, func(g []string) string {
+		return zhDigits(g[1]) + "年"
+	}),
+	// 时间：10:30 和 10:30:15
+	mustRegexRule(`(\d{1,2})[:：](\d{2})(?:[:：](\d{2}))?`, func(g []string) string {
+		if words, ok := zhClock(g[1], g[2], g[3]); ok {
+			return words
+		}
+		return g[0]
+	}),
+	// 货币：¥100、$5.5，金额后面已经写了 "元" 或 "块" 时不再重复
+	mustRegexRule(`([¥￥$€£])\s?(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)(元|块|美元|欧元|英镑)?`, func(g []string) string {
+		if g[3] != "" {
+			return zhNumber(g[2]) + g[3]
+		}
+		return zhNumber(g[2]) + zhCurrencies[g[1]]
+	}),
+	// 百分数
+	mustRegexRule(`(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)\s?[%％]`, func(g []string) string {
+		return "百分之" + zhNumber(g[1])
+	}),
+	// 分数：3/4
+	mustRegexRule(`\b(\d{1,3})/(\d{1,3})\b`, func(g []string) string {
+		denominator, _ := strconv.ParseInt(g[2], 10, 64)
+		if denominator == 0 {
+			return g[0]
+		}
+		return zhNumber(g[2]) + "分之" + zhNumber(g[1])
+	}),
+	// 单位：10kg、20°C，单个字母的单位必须紧跟数字，不能有空格。
+	// "-" 只在开头、空白、括号、标点或汉字之后读作负，"5-10kg" 中的 "-" 是范围
+	mustRegexRule(`((^|[\s(（，、：；\p{Han}])-)?(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?)(?:\s?(℃|(?:km/h|kg|mg|km|cm|mm|ml|mL|°C|°F|kHz|MHz|GHz|Hz|ms|min)\b)|(g|m|l|L|h)\b)`, func(g []string) string {
+		unit := zhUnits[g[4]+g[5]]
+		if g[1] != "" {
+			return g[2] + "负" + zhNumber(g[3]) + unit
+		}
+		return zhNumber(g[3]) + unit
+	}),
+	// 常见英文缩写
+	mustRegexRule(`\b(?:vs|VS)\b\.?|\betc\.|\bNo\.\s?`, func(g []string) string {
+		key := strings.TrimSpace(g[0])
+		if words, ok := zhAbbreviations[key]; ok {
+			return words
+		}
+		return g[0]
+	}),
+	// 剩下的数字，包括 "第1" 这样的序数
+	NormalizerFunc(func(text string) string {
+		return replaceNumbers(text, zhNumber, "负")
+	}),
+}
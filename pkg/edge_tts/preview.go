@@ -0,0 +1,264 @@
+package edge_tts
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPreviewConcurrency is how many voices a Preview synthesizes at once
+const DefaultPreviewConcurrency = 4
+
+// DefaultPreviewClipTimeout is how long a Preview waits for one clip
+const DefaultPreviewClipTimeout = time.Minute
+
+// PreviewIndexFile is the name of the HTML page written by Preview.Save
+const PreviewIndexFile = "index.html"
+
+// sampleTexts are the sample sentences of SampleText, by locale or language
+var sampleTexts = map[string]string{
+	"en":    "Hello! This is a sample of my voice.",
+	"zh":    "你好！这是我的声音示例。",
+	"zh-TW": "你好！這是我的聲音範例。",
+	"zh-HK": "你好！呢個係我把聲嘅示範。",
+	"wuu":   "你好！这是我的声音示例。",
+	"yue":   "你好！呢个系我把声嘅示范。",
+	"ja":    "こんにちは！これは私の声のサンプルです。",
+	"ko":    "안녕하세요! 제 목소리 샘플입니다.",
+	"de":    "Hallo! Das ist eine Probe meiner Stimme.",
+	"fr":    "Bonjour ! Voici un extrait de ma voix.",
+	"es":    "¡Hola! Esta es una muestra de mi voz.",
+	"it":    "Ciao! Questo è un esempio della mia voce.",
+	"pt":    "Olá! Esta é uma amostra da minha voz.",
+	"ru":    "Здравствуйте! Это образец моего голоса.",
+	"uk":    "Привіт! Це зразок мого голосу.",
+	"pl":    "Cześć! To jest próbka mojego głosu.",
+	"cs":    "Dobrý den! Toto je ukázka mého hlasu.",
+	"nl":    "Hallo! Dit is een voorbeeld van mijn stem.",
+	"sv":    "Hej! Det här är ett prov på min röst.",
+	"da":    "Hej! Dette er en prøve på min stemme.",
+	"nb":    "Hei! Dette er en prøve på stemmen min.",
+	"fi":    "Hei! Tämä on näyte äänestäni.",
+	"hu":    "Helló! Ez egy minta a hangomból.",
+	"ro":    "Bună! Aceasta este o mostră a vocii mele.",
+	"el":    "Γεια σας! Αυτό είναι ένα δείγμα της φωνής μου.",
+	"tr":    "Merhaba! Bu benim sesimden bir örnek.",
+	"ar":    "مرحبًا! هذه عينة من صوتي.",
+	"he":    "שלום! זוהי דוגמה של הקול שלי.",
+	"hi":    "नमस्ते! यह मेरी आवाज़ का एक नमूना है।",
+	"id":    "Halo! Ini adalah contoh suara saya.",
+	"ms":    "Helo! Ini ialah contoh suara saya.",
+	"vi":    "Xin chào! Đây là mẫu giọng nói của tôi.",
+	"th":    "สวัสดี! นี่คือตัวอย่างเสียงของฉัน",
+}
+
+// SampleText returns a short sentence in the language of the locale to
+// audition a voice with, English when there is none for the language
+func SampleText(locale string) string {
+	for key, text := range sampleTexts {
+		if strings.EqualFold(key, locale) {
+			return text
+		}
+	}
+	language, _, _, _ := splitLocale(locale)
+	if text, ok := sampleTexts[language]; ok {
+		return text
+	}
+	return sampleTexts["en"]
+}
+
+// Preview synthesizes one sample clip per voice, so voices can be compared
+// by ear
+type Preview struct {
+	Voices []Voice
+	// Text is spoken by every voice, when empty each voice speaks the
+	// SampleText of its locale
+	Text string
+	// Concurrency is how many voices are synthesized at once
+	Concurrency int
+	// ClipTimeout limits the time of each clip, so a stalled connection
+	// fails its voice instead of the whole preview. 0 means no limit.
+	ClipTimeout time.Duration
+	// Options are applied to every clip, e.g. WithRate
+	Options []Option
+
+	stream streamFunc
+}
+
+// NewPreview creates a new Preview with the default concurrency
+func NewPreview(voices []Voice, text string, opts ...Option) *Preview {
+	return &Preview{
+		Voices:      voices,
+		Text:        text,
+		Concurrency: DefaultPreviewConcurrency,
+		ClipTimeout: DefaultPreviewClipTimeout,
+		Options:     opts,
+		stream:      communicateStream,
+	}
+}
+
+// PreviewClip is the result for one voice of a Preview
+type PreviewClip struct {
+	Voice Voice
+	Text  string
+	// File is the clip name inside the preview directory, e.g.
+	// "en-US-AvaNeural.mp3"
+	File     string
+	Duration time.Duration
+	// Err is set when the voice could not be synthesized, File is then empty
+	Err error
+}
+
+// Save writes one MP3 clip per voice into dir, creating it if needed, and
+// an index.html that lists the voices with a player for each clip. A voice
+// that fails is listed with its error and does not stop the others; the
+// error returned is for the directory, the page or a cancelled ctx.
+func (p *Preview) Save(ctx context.Context, dir string) ([]PreviewClip, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	clips := p.synthesize(ctx, dir)
+	if err := ctx.Err(); err != nil {
+		return clips, err
+	}
+
+	f, err := os.Create(filepath.Join(dir, PreviewIndexFile))
+	if err != nil {
+		return clips, err
+	}
+	if err := WritePreviewIndex(f, clips); err != nil {
+		f.Close()
+		return clips, err
+	}
+	return clips, f.Close()
+}
+
+// synthesize writes the clips with at most Concurrency voices at once,
+// the clips are returned in the order of Voices
+func (p *Preview) synthesize(ctx context.Context, dir string) []PreviewClip {
+	stream := p.stream
+	if stream == nil {
+		stream = communicateStream
+	}
+
+	clips := make([]PreviewClip, len(p.Voices))
+	slots := make(chan struct{}, max(p.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, voice := range p.Voices {
+		text := p.Text
+		if text == "" {
+			text = SampleText(voice.Locale)
+		}
+		clips[i] = PreviewClip{Voice: voice, Text: text}
+
+		wg.Add(1)
+		go func(clip *PreviewClip) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				clip.Err = ctx.Err()
+				return
+			}
+
+			clipCtx := ctx
+			if p.ClipTimeout > 0 {
+				var cancel context.CancelFunc
+				clipCtx, cancel = context.WithTimeout(ctx, p.ClipTimeout)
+				defer cancel()
+			}
+			data, err := p.synthesizeClip(clipCtx, stream, clip.Text, clip.Voice.ShortName)
+			if err != nil {
+				clip.Err = err
+				return
+			}
+			file := clip.Voice.ShortName + ".mp3"
+			if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+				clip.Err = err
+				return
+			}
+			clip.File = file
+			clip.Duration = mp3Duration(data)
+		}(&clips[i])
+	}
+	wg.Wait()
+	return clips
+}
+
+// synthesizeClip returns the audio of text spoken by voice
+func (p *Preview) synthesizeClip(ctx context.Context, stream streamFunc, text, voice string) ([]byte, error) {
+	ch, err := stream(ctx, text, voice, p.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for chunk := range ch {
+		switch chunk.Type {
+		case "error":
+			return nil, fmt.Errorf("error during streaming: %s", string(chunk.Data))
+		case "audio":
+			data = append(data, chunk.Data...)
+		}
+	}
+	// The stream stops without an error when ctx is done
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrNoAudioReceived
+	}
+	return data, nil
+}
+
+// previewIndex is the page written by WritePreviewIndex
+var previewIndex = template.Must(template.New("preview").Funcs(template.FuncMap{
+	"join":    strings.Join,
+	"seconds": func(d time.Duration) string { return fmt.Sprintf("%.1fs", d.Seconds()) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Voice preview</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em 0.8em; text-align: left; vertical-align: middle; }
+th { background: #f4f4f4; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>Voice preview</h1>
+<p>{{len .}} voices</p>
+<table>
+<tr><th>Voice</th><th>Locale</th><th>Gender</th><th>Categories</th><th>Personalities</th><th>Styles</th><th>Text</th><th>Sample</th></tr>
+{{- range .}}
+<tr>
+<td>{{.Voice.ShortName}}</td>
+<td>{{.Voice.Locale}}<br>{{.Voice.LocaleName}}</td>
+<td>{{.Voice.Gender}}</td>
+<td>{{join .Voice.VoiceTag.ContentCategories ", "}}</td>
+<td>{{join .Voice.VoiceTag.VoicePersonalities ", "}}</td>
+<td>{{join .Voice.StyleList ", "}}</td>
+<td>{{.Text}}</td>
+<td>{{if .Err}}<span class="error">{{.Err}}</span>{{else}}<audio controls preload="none" src="{{.File}}"></audio> {{seconds .Duration}}{{end}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WritePreviewIndex writes an HTML page listing the clips with their voice
+// details and an audio player, the clip files are linked relative to the page
+func WritePreviewIndex(w io.Writer, clips []PreviewClip) error {
+	return previewIndex.Execute(w, clips)
+}
@@ -0,0 +1,144 @@
+package edge_tts
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSampleText 测试按地区选择示例句子
+func TestSampleText(t *testing.T) {
+	tests := map[string]string{
+		"zh-CN": sampleTexts["zh"],
+		"zh-tw": sampleTexts["zh-TW"],
+		"de-AT": sampleTexts["de"],
+		"en-GB": sampleTexts["en"],
+		"xx-YY": sampleTexts["en"],
+	}
+	for locale, want := range tests {
+		if got := SampleText(locale); got != want {
+			t.Errorf("SampleText(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+// TestPreviewSave 测试并发生成试听片段和索引页
+func TestPreviewSave(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	texts := map[string]string{}
+	stream := func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+		if voice == "en-GB-RyanNeural" {
+			return nil, errors.New("voice unavailable")
+		}
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		texts[voice] = text
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		ch := make(chan TTSChunk, 2)
+		ch <- TTSChunk{Type: "audio", Data: silentMP3(480 * time.Millisecond)}
+		ch <- TTSChunk{Type: "end"}
+		close(ch)
+		return ch, nil
+	}
+
+	dir := filepath.Join(t.TempDir(), "preview")
+	p := NewPreview(testVoices, "")
+	p.Concurrency = 2
+	p.stream = stream
+	clips, err := p.Save(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if peak > 2 {
+		t.Errorf("%d voices synthesized at once, want at most 2", peak)
+	}
+	if len(clips) != len(testVoices) {
+		t.Fatalf("got %d clips, want %d", len(clips), len(testVoices))
+	}
+	for i, clip := range clips {
+		name := testVoices[i].ShortName
+		if clip.Voice.ShortName != name {
+			t.Errorf("clip %d is %s, want %s", i, clip.Voice.ShortName, name)
+		}
+		if name == "en-GB-RyanNeural" {
+			if clip.Err == nil || clip.File != "" {
+				t.Errorf("failed clip = %+v, want an error and no file", clip)
+			}
+			continue
+		}
+		if clip.Err != nil {
+			t.Errorf("clip %s error = %v", name, clip.Err)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, clip.File)); err != nil {
+			t.Errorf("clip %s not written: %v", name, err)
+		}
+		if clip.Duration != 480*time.Millisecond {
+			t.Errorf("clip %s duration = %v, want 480ms", name, clip.Duration)
+		}
+	}
+	if got := texts["zh-CN-XiaoxiaoNeural"]; got != SampleText("zh-CN") {
+		t.Errorf("Xiaoxiao spoke %q, want the Chinese sample", got)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, PreviewIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(index)
+	for _, want := range []string{
+		`<audio controls preload="none" src="en-US-AriaNeural.mp3">`,
+		"News, Novel",
+		"cheerful, sad",
+		"voice unavailable",
+		"你好",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("index.html does not contain %q", want)
+		}
+	}
+}
+
+// TestPreviewClipTimeout 测试卡住的语音超时后只让这个语音失败
+func TestPreviewClipTimeout(t *testing.T) {
+	stream := func(ctx context.Context, text, voice string, opts ...Option) (<-chan TTSChunk, error) {
+		ch := make(chan TTSChunk, 2)
+		go func() {
+			defer close(ch)
+			if voice == "en-GB-RyanNeural" {
+				<-ctx.Done()
+				return
+			}
+			ch <- TTSChunk{Type: "audio", Data: silentMP3(480 * time.Millisecond)}
+			ch <- TTSChunk{Type: "end"}
+		}()
+		return ch, nil
+	}
+
+	p := NewPreview(testVoices, "")
+	p.ClipTimeout = 50 * time.Millisecond
+	p.stream = stream
+	clips, err := p.Save(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, clip := range clips {
+		stalled := clip.Voice.ShortName == "en-GB-RyanNeural"
+		if stalled != errors.Is(clip.Err, context.DeadlineExceeded) {
+			t.Errorf("clip %s error = %v", clip.Voice.ShortName, clip.Err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package edge_tts
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+)
+
+// ReadabilityOptions 是字幕可读性规则，0 表示不使用该规则
+type ReadabilityOptions struct {
+	// MinDuration 是每条字幕最短的显示时间
+	MinDuration time.Duration
+	// MaxCPS 是每秒最多的字符数（不计空白），字幕太短时延长显示时间
+	MaxCPS float64
+	// MinGap 是相邻字幕之间最小的间隔，字幕延长时不会超过、太近时缩短到
+	// 下一条字幕开始前的 MinGap
+	MinGap time.Duration
+	// CloseGap 内的间隔会被填上，字幕一直显示到下一条字幕开始前的 MinGap
+	CloseGap time.Duration
+}
+
+// DefaultReadabilityOptions 返回常见广播字幕规范的设置：至少显示 5/6 秒，
+// 每秒不超过 17 个字符，字幕之间间隔 2 帧（24fps），半秒内的间隔会被填上
+func DefaultReadabilityOptions() ReadabilityOptions {
+	return ReadabilityOptions{
+		MinDuration: 833 * time.Millisecond,
+		MaxCPS:      17,
+		MinGap:      83 * time.Millisecond,
+		CloseGap:    500 * time.Millisecond,
+	}
+}
+
+// ApplyReadability 按 opts 调整字幕的结束时间，让字幕显示得足够久。
+// 字幕只会延长到静音中，不会与下一条字幕重叠；与下一条字幕的间隔
+// 不到 MinGap 时缩短，MinGap 优先于 MinDuration。下一条字幕开始得太早、
+// 留不出 MinGap 时，字幕显示到下一条字幕开始为止。开始时间不变。
+// 它应该在 MergeCues 之后、生成字幕之前调用。
+func (s *SubMaker) ApplyReadability(opts ReadabilityOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	for i := range s.cues {
+		var next *SubCue
+		if i+1 < len(s.cues) {
+			next = &s.cues[i+1]
+		}
+		s.cues[i].End = opts.end(s.cues[i], next)
+	}
+	return nil
+}
+
+// validate 检查选项
+func (opts ReadabilityOptions) validate() error {
+	if opts.MinDuration < 0 || opts.MaxCPS < 0 || opts.MinGap < 0 || opts.CloseGap < 0 {
+		return fmt.Errorf("invalid readability options, values must not be negative")
+	}
+	return nil
+}
+
+// end 返回 cue 延长后的结束时间，next 是下一条字幕，没有时为 nil
+func (opts ReadabilityOptions) end(cue SubCue, next *SubCue) time.Duration {
+	end := cue.Start + opts.MinDuration
+	if opts.MaxCPS > 0 {
+		chars := readableChars(cue.Text)
+		end = max(end, cue.Start+time.Duration(float64(chars)/opts.MaxCPS*float64(time.Second)))
+	}
+	if next == nil {
+		// 只延长，不缩短
+		return max(cue.End, end)
+	}
+
+	// 下一条字幕开始前 MinGap 是结束时间的上限
+	limit := next.Start - opts.MinGap
+	if limit-max(end, cue.End) < opts.CloseGap {
+		end = limit
+	}
+	end = max(cue.End, min(end, limit))
+	if end <= limit {
+		return end
+	}
+
+	// 与下一条字幕的间隔不到 MinGap 时缩短，即使比 MinDuration 短
+	switch {
+	case limit > cue.Start:
+		return limit
+	case next.Start > cue.Start:
+		// 留不出 MinGap，至少不与下一条字幕重叠
+		return next.Start
+	default:
+		// 两条字幕同时开始，无法避免重叠
+		return cue.End
+	}
+}
+
+// readableChars 返回字幕中不是空白的字符数
+func readableChars(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+package edge_tts
+
+import (
+	"testing"
+	"time"
+)
+
+// TestApplyReadability 测试字幕可读性规则
+func TestApplyReadability(t *testing.T) {
+	ms := time.Millisecond
+	cue := func(start, end time.Duration, text string) SubCue {
+		return SubCue{Start: start * ms, End: end * ms, Text: text}
+	}
+
+	tests := []struct {
+		name string
+		cues []SubCue
+		opts ReadabilityOptions
+		want []time.Duration // 每条字幕的结束时间（毫秒）
+	}{
+		{
+			name: "min duration extends into silence",
+			cues: []SubCue{cue(0, 200, "Hi"), cue(2000, 2200, "there")},
+			opts: ReadabilityOptions{MinDuration: 1000 * ms},
+			want: []time.Duration{1000, 3000},
+		},
+		{
+			name: "extension stops before the next cue",
+			cues: []SubCue{cue(0, 200, "Hi"), cue(600, 800, "there")},
+			opts: ReadabilityOptions{MinDuration: 1000 * ms, MinGap: 100 * ms},
+			want: []time.Duration{500, 1600},
+		},
+		{
+			name: "characters per second",
+			cues: []SubCue{cue(0, 500, "这是一个很长的句子"), cue(5000, 5100, "a b")},
+			opts: ReadabilityOptions{MaxCPS: 4},
+			want: []time.Duration{2250, 5500},
+		},
+		{
+			name: "short gaps are closed",
+			cues: []SubCue{cue(0, 1000, "one"), cue(1300, 2000, "two"), cue(3000, 4000, "three")},
+			opts: ReadabilityOptions{MinGap: 80 * ms, CloseGap: 500 * ms},
+			want: []time.Duration{1220, 2000, 4000},
+		},
+		{
+			name: "touching cues are trimmed to the gap",
+			cues: []SubCue{cue(0, 1000, "one"), cue(1000, 2000, "two")},
+			opts: ReadabilityOptions{MinGap: 100 * ms},
+			want: []time.Duration{900, 2000},
+		},
+		{
+			name: "min gap wins over the min duration",
+			cues: []SubCue{cue(0, 1000, "one"), cue(1000, 2000, "two")},
+			opts: ReadabilityOptions{MinDuration: 950 * ms, MinGap: 100 * ms},
+			want: []time.Duration{900, 2000},
+		},
+		{
+			name: "min duration never overlaps the next cue",
+			cues: []SubCue{cue(0, 1000, "one"), cue(500, 2000, "two")},
+			opts: ReadabilityOptions{MinDuration: 833 * ms},
+			want: []time.Duration{500, 2000},
+		},
+		{
+			name: "short word cues keep the min gap",
+			cues: []SubCue{cue(0, 200, "Hi"), cue(250, 450, "there"), cue(3000, 3200, "again")},
+			opts: DefaultReadabilityOptions(),
+			want: []time.Duration{167, 1083, 3833},
+		},
+		{
+			name: "next cue too close for the min gap",
+			cues: []SubCue{cue(0, 200, "Hi"), cue(50, 450, "there")},
+			opts: DefaultReadabilityOptions(),
+			want: []time.Duration{50, 883},
+		},
+		{
+			name: "overlapping cues are trimmed",
+			cues: []SubCue{cue(0, 1000, "one"), cue(950, 2000, "two")},
+			opts: DefaultReadabilityOptions(),
+			want: []time.Duration{867, 2000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &SubMaker{cues: tt.cues}
+			if err := sub.ApplyReadability(tt.opts); err != nil {
+				t.Fatalf("ApplyReadability() error = %v", err)
+			}
+			for i, want := range tt.want {
+				if got := sub.cues[i].End; got != want*ms {
+					t.Errorf("cue %d end = %v, want %v", i, got, want*ms)
+				}
+				if sub.cues[i].Start != tt.cues[i].Start {
+					t.Errorf("cue %d start changed", i)
+				}
+			}
+		})
+	}
+
+	if err := NewSubMaker().ApplyReadability(ReadabilityOptions{MaxCPS: -1}); err == nil {
+		t.Error("ApplyReadability() should reject negative values")
+	}
+}
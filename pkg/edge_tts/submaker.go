@@ -9,14 +9,31 @@ import (
 // SubMaker 用于生成字幕
 type SubMaker struct {
 	cues []SubCue
+	// lrcTags 是 Save 填入的合成信息，GetSubtitles 生成 LRC 时使用
+	lrcTags LRCTags
+	// boundaries 是所有单词和句子边界，用于导出时间
+	boundaries []boundary
 }
 
 // SubCue 表示一个字幕片段
 type SubCue struct {
-	Index int
+	Index   int
+	Start   time.Duration
+	End     time.Duration
+	Text    string
+	Speaker string    // 说话人，用于对话字幕
+	Words   []SubWord // 字幕中每个词的时间，用于逐词高亮
+}
+
+// SubWord 表示字幕中一个词的时间
+type SubWord struct {
 	Start time.Duration
 	End   time.Duration
 	Text  string
+	// textOffset 和 textLength 是服务报告的词在原文中的字符位置，
+	// textLength 为 0 时没有报告
+	textOffset int
+	textLength int
 }
 
 // NewSubMaker 创建一个新的 SubMaker
@@ -26,10 +43,10 @@ func NewSubMaker() *SubMaker {
 	}
 }
 
-// Feed 添加一个字幕片段
+// Feed 添加一个字幕片段，SentenceBoundary 只记录时间，不生成字幕
 func (s *SubMaker) Feed(chunk TTSChunk) error {
-	if chunk.Type != "WordBoundary" {
-		return fmt.Errorf("invalid message type, expected 'WordBoundary'")
+	if chunk.Type != "WordBoundary" && chunk.Type != "SentenceBoundary" {
+		return fmt.Errorf("invalid message type, expected 'WordBoundary' or 'SentenceBoundary'")
 	}
 
 	// 检查文本内容是否为空
@@ -37,66 +54,64 @@ func (s *SubMaker) Feed(chunk TTSChunk) error {
 		return nil
 	}
 
-	// 创建新的字幕片段，注意时间单位转换为微秒
+	b := newBoundary(chunk)
+	s.boundaries = append(s.boundaries, b)
+	if b.kind == BoundarySentence {
+		return nil
+	}
+	start, end := b.start, b.end
+
+	// 创建新的字幕片段
 	cue := SubCue{
 		Index: len(s.cues) + 1, // SRT格式要求从1开始
-		Start: time.Duration(float64(chunk.Offset/10)) * time.Microsecond,
-		End:   time.Duration(float64((chunk.Offset+chunk.Duration)/10)) * time.Microsecond,
+		Start: start,
+		End:   end,
 		Text:  chunk.Text,
 	}
+	word := SubWord{Start: cue.Start, End: cue.End, Text: cue.Text}
+	if chunk.TextOffset >= 0 && chunk.TextLength > 0 {
+		word.textOffset, word.textLength = chunk.TextOffset, chunk.TextLength
+	}
+	cue.Words = []SubWord{word}
 
 	// 添加到字幕列表
 	s.cues = append(s.cues, cue)
 	return nil
 }
 
-// MergeCues 合并字幕片段
+// MergeCues 合并字幕片段，每条字幕最多 words 个词
 func (s *SubMaker) MergeCues(words int) error {
 	if words <= 0 {
 		return fmt.Errorf("invalid number of words to merge, expected > 0")
 	}
-
-	if len(s.cues) == 0 {
-		return nil
-	}
-
-	newCues := make([]SubCue, 0)
-	currentCue := s.cues[0]
-
-	for _, cue := range s.cues[1:] {
-		// 计算当前字幕中的单词数
-		wordCount := len(strings.Fields(currentCue.Text))
-		if wordCount < words {
-			// 合并字幕
-			currentCue = SubCue{
-				Index: currentCue.Index,
-				Start: currentCue.Start,
-				End:   cue.End,
-				Text:  currentCue.Text + " " + cue.Text,
-			}
-		} else {
-			newCues = append(newCues, currentCue)
-			currentCue = cue
-		}
-	}
-	newCues = append(newCues, currentCue)
-	s.cues = newCues
-	return nil
+	return s.MergeCuesWith(MergeOptions{MaxWords: words})
 }
 
 // GetSRT 生成SRT格式的字幕
 func (s *SubMaker) GetSRT() string {
-	var result string
+	var b strings.Builder
+	// 跳过的字幕不占序号，写入的字幕从 1 开始连续编号
+	index := 0
 	for _, cue := range s.cues {
 		// 跳过空文本的字幕
 		if cue.Text == "" {
 			continue
 		}
-		result += fmt.Sprintf("%d\n", cue.Index)
-		result += fmt.Sprintf("%s --> %s\n", formatDuration(cue.Start), formatDuration(cue.End))
-		result += cue.Text + "\n\n"
+		index++
+		writeSRTCue(&b, index, cue)
+	}
+	return b.String()
+}
+
+// writeSRTCue 写入一条 SRT 字幕
+func writeSRTCue(b *strings.Builder, index int, cue SubCue) {
+	fmt.Fprintf(b, "%d\n", index)
+	fmt.Fprintf(b, "%s --> %s\n", formatDuration(cue.Start), formatDuration(cue.End))
+	// 对话字幕以说话人名字作为前缀
+	if cue.Speaker != "" {
+		b.WriteString(cue.Speaker + ": ")
 	}
-	return result
+	b.WriteString(cue.Text + "\n\n")
 }
 
 // formatDuration 格式化时间持续时间
@@ -107,3 +122,139 @@ func formatDuration(d time.Duration) string {
 	milliseconds := int(d.Milliseconds()) % 1000
 	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, seconds, milliseconds)
 }
+
+// VTTOption 是 GetVTT 的选项
+type VTTOption func(*vttConfig)
+
+// vttConfig 保存 WebVTT 的字幕设置
+type vttConfig struct {
+	position string
+	line     string
+	align    string
+	words    bool
+}
+
+// WithVTTPosition 设置字幕的水平位置，例如 "50%"
+func WithVTTPosition(position string) VTTOption {
+	return func(c *vttConfig) {
+		c.position = position
+	}
+}
+
+// WithVTTLine 设置字幕的行位置，例如 "90%" 或 "-1"
+func WithVTTLine(line string) VTTOption {
+	return func(c *vttConfig) {
+		c.line = line
+	}
+}
+
+// WithVTTAlign 设置字幕的对齐方式：start、center、end、left 或 right
+func WithVTTAlign(align string) VTTOption {
+	return func(c *vttConfig) {
+		c.align = align
+	}
+}
+
+// WithVTTWordTimestamps 为每个词添加时间戳和 <c> 标签，用于卡拉 OK 式逐词高亮
+func WithVTTWordTimestamps() VTTOption {
+	return func(c *vttConfig) {
+		c.words = true
+	}
+}
+
+// settings 返回 cue 时间后面的字幕设置
+func (c *vttConfig) settings() string {
+	var settings []string
+	if c.position != "" {
+		settings = append(settings, "position:"+c.position)
+	}
+	if c.line != "" {
+		settings = append(settings, "line:"+c.line)
+	}
+	if c.align != "" {
+		settings = append(settings, "align:"+c.align)
+	}
+	if len(settings) == 0 {
+		return ""
+	}
+	return " " + strings.Join(settings, " ")
+}
+
+// vttEscaper 转义 WebVTT 字幕文本中的特殊字符
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// newVTTConfig 返回应用了 opts 的设置
+func newVTTConfig(opts ...VTTOption) *vttConfig {
+	config := &vttConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// GetVTT 生成 WebVTT 格式的字幕
+func (s *SubMaker) GetVTT(opts ...VTTOption) string {
+	config := newVTTConfig(opts...)
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	// 跳过的字幕不占序号，写入的字幕从 1 开始连续编号
+	index := 0
+	for _, cue := range s.cues {
+		// 跳过空文本的字幕
+		if cue.Text == "" {
+			continue
+		}
+		index++
+		config.writeCue(&b, index, cue)
+	}
+	return b.String()
+}
+
+// writeCue 写入一条 WebVTT 字幕
+func (c *vttConfig) writeCue(b *strings.Builder, index int, cue SubCue) {
+	fmt.Fprintf(b, "%d\n", index)
+	fmt.Fprintf(b, "%s --> %s%s\n", formatVTTDuration(cue.Start), formatVTTDuration(cue.End), c.settings())
+	// 对话字幕使用 <v> 标签标记说话人
+	if cue.Speaker != "" {
+		fmt.Fprintf(b, "<v %s>", vttEscaper.Replace(cue.Speaker))
+	}
+	if c.words && len(cue.Words) > 0 {
+		// 按 MergeCuesWith 换行后的文本换行
+		breaks := wordLineBreaks(cue)
+		for i, word := range cue.Words {
+			if i > 0 {
+				if breaks != nil && breaks[i] {
+					b.WriteString("\n")
+				} else {
+					b.WriteString(cueSeparator(cue.Words[i-1].Text, word.Text))
+				}
+				// 第一个词从 cue 开始时高亮，不需要时间戳
+				fmt.Fprintf(b, "<%s>", formatVTTDuration(word.Start))
+			}
+			fmt.Fprintf(b, "<c>%s</c>", vttEscaper.Replace(word.Text))
+		}
+	} else {
+		b.WriteString(vttEscaper.Replace(cue.Text))
+	}
+	b.WriteString("\n\n")
+}
+
+// GetSubtitles 根据文件扩展名生成字幕：.vtt 生成 WebVTT，.ass 和 .ssa
+// 生成默认样式的 ASS，.lrc 生成带合成信息标签的 LRC，其他生成 SRT
+func (s *SubMaker) GetSubtitles(path string) string {
+	switch SubtitleFormatForPath(path) {
+	case SubtitleVTT:
+		return s.GetVTT()
+	case SubtitleASS:
+		return s.GetASS(DefaultASSStyle())
+	case SubtitleLRC:
+		return s.GetLRC(WithLRCTags(s.lrcTags))
+	}
+	return s.GetSRT()
+}
+
+// formatVTTDuration 格式化 WebVTT 的时间，毫秒以 "." 分隔
+func formatVTTDuration(d time.Duration) string {
+	return strings.Replace(formatDuration(d), ",", ".", 1)
+}
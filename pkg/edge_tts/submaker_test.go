@@ -0,0 +1,61 @@
+package edge_tts
+
+import (
+	"testing"
+)
+
+// newTestSubMaker 返回按顺序包含 words 的 SubMaker，每个词 200ms，间隔 40ms
+func newTestSubMaker(t *testing.T, words ...string) *SubMaker {
+	t.Helper()
+	sub := NewSubMaker()
+	for i, word := range words {
+		err := sub.Feed(TTSChunk{
+			Type:     "WordBoundary",
+			Offset:   float64(i) * 2400000,
+			Duration: 2000000,
+			Text:     word,
+		})
+		if err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+	}
+	return sub
+}
+
+// TestGetVTT 测试生成 WebVTT 字幕
+func TestGetVTT(t *testing.T) {
+	sub := newTestSubMaker(t, "Fish", "&", "chips")
+	if err := sub.MergeCues(10); err != nil {
+		t.Fatalf("MergeCues() error = %v", err)
+	}
+
+	want := "WEBVTT\n\n" +
+		"1\n" +
+		"00:00:00.000 --> 00:00:00.680\n" +
+		"Fish &amp; chips\n\n"
+	if got := sub.GetVTT(); got != want {
+		t.Errorf("GetVTT() = %q, want %q", got, want)
+	}
+
+	want = "WEBVTT\n\n" +
+		"1\n" +
+		"00:00:00.000 --> 00:00:00.680 position:50% line:90% align:center\n" +
+		"<c>Fish</c> <00:00:00.240><c>&amp;</c> <00:00:00.480><c>chips</c>\n\n"
+	got := sub.GetVTT(WithVTTPosition("50%"), WithVTTLine("90%"), WithVTTAlign("center"), WithVTTWordTimestamps())
+	if got != want {
+		t.Errorf("GetVTT() with options = %q, want %q", got, want)
+	}
+}
+
+// TestGetSubtitles 测试根据扩展名选择字幕格式
+func TestGetSubtitles(t *testing.T) {
+	sub := newTestSubMaker(t, "Hello")
+	sub.cues[0].Speaker = "Alice"
+
+	if got, want := sub.GetSubtitles("out.VTT"), "WEBVTT\n\n1\n00:00:00.000 --> 00:00:00.200\n<v Alice>Hello\n\n"; got != want {
+		t.Errorf("GetSubtitles(.vtt) = %q, want %q", got, want)
+	}
+	if got, want := sub.GetSubtitles("out.srt"), "1\n00:00:00,000 --> 00:00:00,200\nAlice: Hello\n\n"; got != want {
+		t.Errorf("GetSubtitles(.srt) = %q, want %q", got, want)
+	}
+}
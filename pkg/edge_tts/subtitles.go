@@ -0,0 +1,268 @@
+package edge_tts
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseSRT 读取 SRT 字幕
+func ParseSRT(r io.Reader) (*SubMaker, error) {
+	blocks, err := readSubtitleBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewSubMaker()
+	for _, block := range blocks {
+		// 序号行是可选的
+		if len(block.lines) > 0 && !strings.Contains(block.lines[0], "-->") {
+			block.lines = block.lines[1:]
+			block.line++
+		}
+		if len(block.lines) == 0 {
+			continue
+		}
+		start, end, err := parseCueTiming(block.lines[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", block.line, err)
+		}
+		s.cues = append(s.cues, SubCue{
+			Index: len(s.cues) + 1,
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block.lines[1:], "\n"),
+		})
+	}
+	return s, nil
+}
+
+// ParseVTT 读取 WebVTT 字幕，<v> 标签中的名字作为说话人，其他标签和字幕设置被去掉
+func ParseVTT(r io.Reader) (*SubMaker, error) {
+	blocks, err := readSubtitleBlocks(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 || !strings.HasPrefix(blocks[0].lines[0], "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	s := NewSubMaker()
+	for _, block := range blocks[1:] {
+		// 跳过注释、样式和区域定义
+		if first := block.lines[0]; first == "NOTE" || strings.HasPrefix(first, "NOTE ") ||
+			first == "STYLE" || first == "REGION" {
+			continue
+		}
+		// cue 标识是可选的
+		if !strings.Contains(block.lines[0], "-->") {
+			block.lines = block.lines[1:]
+			block.line++
+		}
+		if len(block.lines) == 0 {
+			continue
+		}
+		start, end, err := parseCueTiming(block.lines[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", block.line, err)
+		}
+
+		text := strings.Join(block.lines[1:], "\n")
+		speaker := ""
+		if m := vttVoicePattern.FindStringSubmatch(text); m != nil {
+			speaker = html.UnescapeString(strings.TrimSpace(m[1]))
+		}
+		s.cues = append(s.cues, SubCue{
+			Index:   len(s.cues) + 1,
+			Start:   start,
+			End:     end,
+			Text:    html.UnescapeString(vttTagPattern.ReplaceAllString(text, "")),
+			Speaker: speaker,
+		})
+	}
+	return s, nil
+}
+
+var (
+	// vttVoicePattern 匹配字幕开头的 <v Name> 或 <v.class Name> 标签
+	vttVoicePattern = regexp.MustCompile(`^<v(?:\.[^ \t>]*)?[ \t]+([^>]*)>`)
+	// vttTagPattern 匹配 WebVTT 的标签和时间戳
+	vttTagPattern = regexp.MustCompile(`</?[^>]*>`)
+)
+
+// LoadSubtitles 读取 SRT 或 WebVTT 字幕文件，.vtt 文件或以 WEBVTT 开头的文件按 WebVTT 解析
+func LoadSubtitles(path string) (*SubMaker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\uFEFF")
+	if strings.EqualFold(filepath.Ext(path), ".vtt") || strings.HasPrefix(text, "WEBVTT") {
+		return ParseVTT(strings.NewReader(text))
+	}
+	return ParseSRT(strings.NewReader(text))
+}
+
+// subtitleBlock 是字幕文件中以空行分隔的一段
+type subtitleBlock struct {
+	line  int // 第一行的行号
+	lines []string
+}
+
+// readSubtitleBlocks 按空行把字幕文件分段
+func readSubtitleBlocks(r io.Reader) ([]subtitleBlock, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var blocks []subtitleBlock
+	var current *subtitleBlock
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			blocks = append(blocks, subtitleBlock{line: n})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	return blocks, scanner.Err()
+}
+
+// parseCueTiming 解析 "00:00:01,000 --> 00:00:02,500 align:start" 这样的时间行，
+// 忽略后面的字幕设置
+func parseCueTiming(line string) (time.Duration, time.Duration, error) {
+	from, rest, ok := strings.Cut(line, "-->")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid cue timing %q", line)
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid cue timing %q", line)
+	}
+	start, err := parseTimestamp(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseTimestamp 解析 "hh:mm:ss,mmm"、"hh:mm:ss.mmm" 和 "mm:ss.mmm" 格式的时间
+func parseTimestamp(s string) (time.Duration, error) {
+	clock, frac, _ := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 || len(frac) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	var d time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		d = d*60 + time.Duration(n)
+	}
+	d *= time.Second
+	if frac != "" {
+		ms, err := strconv.Atoi((frac + "00")[:3])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		d += time.Duration(ms) * time.Millisecond
+	}
+	return d, nil
+}
+
+// Cues 返回字幕片段的副本
+func (s *SubMaker) Cues() []SubCue {
+	cues := make([]SubCue, len(s.cues))
+	for i, cue := range s.cues {
+		cue.Words = append([]SubWord(nil), cue.Words...)
+		cues[i] = cue
+	}
+	return cues
+}
+
+// End 返回最后一条字幕的结束时间
+func (s *SubMaker) End() time.Duration {
+	var end time.Duration
+	for _, cue := range s.cues {
+		end = max(end, cue.End)
+	}
+	return end
+}
+
+// Shift 把所有字幕移动 offset，移到 0 之前的时间被截断为 0，
+// 完全移到 0 之前的字幕被删除
+func (s *SubMaker) Shift(offset time.Duration) {
+	shift := func(d time.Duration) time.Duration {
+		return max(d+offset, 0)
+	}
+
+	cues := s.cues[:0]
+	for _, cue := range s.cues {
+		if cue.End+offset <= 0 && offset < 0 {
+			continue
+		}
+		cue.Start, cue.End = shift(cue.Start), shift(cue.End)
+		words := make([]SubWord, len(cue.Words))
+		for i, word := range cue.Words {
+			word.Start, word.End = shift(word.Start), shift(word.End)
+			words[i] = word
+		}
+		cue.Words = words
+		cues = append(cues, cue)
+	}
+	s.cues = cues
+	for i := range s.boundaries {
+		s.boundaries[i].start = shift(s.boundaries[i].start)
+		s.boundaries[i].end = shift(s.boundaries[i].end)
+	}
+}
+
+// Renumber 把字幕序号重新编为 1, 2, 3...
+func (s *SubMaker) Renumber() {
+	for i := range s.cues {
+		s.cues[i].Index = i + 1
+	}
+}
+
+// Append 把 other 的字幕移动 offset 后追加到后面，并重新编号，
+// 用于拼接多段音频的字幕
+func (s *SubMaker) Append(other *SubMaker, offset time.Duration) {
+	shifted := &SubMaker{cues: other.Cues()}
+	shifted.Shift(offset)
+	s.cues = append(s.cues, shifted.cues...)
+	s.Renumber()
+}
+
+// MergeTracks 把多个字幕轨道合并为一个，字幕按开始时间排序并重新编号
+func MergeTracks(tracks ...*SubMaker) *SubMaker {
+	merged := NewSubMaker()
+	for _, track := range tracks {
+		merged.cues = append(merged.cues, track.Cues()...)
+	}
+	sort.SliceStable(merged.cues, func(i, j int) bool {
+		return merged.cues[i].Start < merged.cues[j].Start
+	})
+	merged.Renumber()
+	return merged
+}
@@ -0,0 +1,94 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseSRT 测试读取 SRT 字幕
+func TestParseSRT(t *testing.T) {
+	src := "\uFEFF1\r\n00:00:01,000 --> 00:00:02,500\r\nHello\r\nworld\r\n\r\n" +
+		"7\n00:01:02.250 --> 00:01:03,000\nBye\n\n\n"
+	sub, err := ParseSRT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseSRT() error = %v", err)
+	}
+	want := "1\n00:00:01,000 --> 00:00:02,500\nHello\nworld\n\n" +
+		"2\n00:01:02,250 --> 00:01:03,000\nBye\n\n"
+	if got := sub.GetSRT(); got != want {
+		t.Errorf("GetSRT() = %q, want %q", got, want)
+	}
+
+	if _, err := ParseSRT(strings.NewReader("1\n00:00:01 -> 00:00:02\nHi\n")); err == nil {
+		t.Error("ParseSRT() should reject invalid timings")
+	}
+}
+
+// TestParseVTT 测试读取 WebVTT 字幕
+func TestParseVTT(t *testing.T) {
+	src := "WEBVTT - demo\n\nNOTE a comment\n\nSTYLE\n::cue { color: red }\n\n" +
+		"intro\n00:01.000 --> 00:02.000 align:start\n<v Alice>Fish <00:01.500><c>&amp;</c> chips\n\n" +
+		"00:00:03.000 --> 00:00:04.000\nPlain\n"
+	sub, err := ParseVTT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseVTT() error = %v", err)
+	}
+	cues := sub.Cues()
+	if len(cues) != 2 {
+		t.Fatalf("Cues() = %+v", cues)
+	}
+	if cues[0].Speaker != "Alice" || cues[0].Text != "Fish & chips" || cues[0].Start != time.Second {
+		t.Errorf("cue 1 = %+v", cues[0])
+	}
+	if cues[1].Text != "Plain" || cues[1].End != 4*time.Second {
+		t.Errorf("cue 2 = %+v", cues[1])
+	}
+
+	// GetVTT 的输出可以读回来
+	again, err := ParseVTT(strings.NewReader(sub.GetVTT()))
+	if err != nil || again.GetSRT() != sub.GetSRT() {
+		t.Errorf("ParseVTT(GetVTT()) = %q, %v", again.GetSRT(), err)
+	}
+
+	if _, err := ParseVTT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nHi\n")); err == nil {
+		t.Error("ParseVTT() should require the WEBVTT header")
+	}
+}
+
+// TestSubtitleEditing 测试移动、拼接和合并字幕
+func TestSubtitleEditing(t *testing.T) {
+	a := newTestSubMaker(t, "one", "two")
+	b := newTestSubMaker(t, "three")
+
+	a.Append(b, a.End()+time.Second)
+	want := "1\n00:00:00,000 --> 00:00:00,200\none\n\n" +
+		"2\n00:00:00,240 --> 00:00:00,440\ntwo\n\n" +
+		"3\n00:00:01,440 --> 00:00:01,640\nthree\n\n"
+	if got := a.GetSRT(); got != want {
+		t.Errorf("Append() = %q, want %q", got, want)
+	}
+
+	a.Shift(-300 * time.Millisecond)
+	a.Renumber()
+	want = "1\n00:00:00,000 --> 00:00:00,140\ntwo\n\n" +
+		"2\n00:00:01,140 --> 00:00:01,340\nthree\n\n"
+	if got := a.GetSRT(); got != want {
+		t.Errorf("Shift() = %q, want %q", got, want)
+	}
+
+	c := newTestSubMaker(t, "x")
+	c.Shift(time.Second)
+	merged := MergeTracks(a, c)
+	var texts []string
+	for _, cue := range merged.Cues() {
+		texts = append(texts, cue.Text)
+	}
+	if got := strings.Join(texts, ","); got != "two,x,three" {
+		t.Errorf("MergeTracks() = %s", got)
+	}
+	// 合并不修改原来的轨道
+	if len(a.Cues()) != 2 {
+		t.Errorf("MergeTracks() changed its input")
+	}
+}
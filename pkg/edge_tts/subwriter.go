@@ -0,0 +1,204 @@
+package edge_tts
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SubtitleFormat 是字幕文件的格式
+type SubtitleFormat string
+
+// 支持的字幕格式
+const (
+	SubtitleSRT SubtitleFormat = "srt"
+	SubtitleVTT SubtitleFormat = "vtt"
+	SubtitleASS SubtitleFormat = "ass"
+	SubtitleLRC SubtitleFormat = "lrc"
+)
+
+// SubtitleFormatForPath 根据文件扩展名返回字幕格式：.vtt 为 WebVTT，
+// .ass 和 .ssa 为 ASS，.lrc 为 LRC，其他为 SRT
+func SubtitleFormatForPath(path string) SubtitleFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vtt":
+		return SubtitleVTT
+	case ".ass", ".ssa":
+		return SubtitleASS
+	case ".lrc":
+		return SubtitleLRC
+	}
+	return SubtitleSRT
+}
+
+// SubtitleWriterOptions 是 SubtitleWriter 的设置
+type SubtitleWriterOptions struct {
+	// Merge 把单词合并为字幕，nil 时每个词一条字幕
+	Merge *MergeOptions
+	// Readability 延长过短的字幕，nil 时不延长
+	Readability *ReadabilityOptions
+	// VTT 是 WebVTT 的字幕设置
+	VTT []VTTOption
+	// ASSStyle 是 ASS 的样式，nil 时使用 DefaultASSStyle
+	ASSStyle *ASSStyle
+}
+
+// SubtitleWriter 在收到 WordBoundary 时把完成的字幕写入 io.Writer，
+// 用于在合成过程中显示字幕。一条字幕在下一个词不能再合并进来时完成，
+// 合成结束后需要调用 Flush 写入最后一条字幕。
+type SubtitleWriter struct {
+	w           io.Writer
+	format      SubtitleFormat
+	merger      *cueMerger
+	readability *ReadabilityOptions
+	vtt         *vttConfig
+	assStyle    ASSStyle
+
+	current *SubCue // 还在合并的字幕
+	index   int     // 已写入的字幕数
+	started bool    // 是否已写入文件头
+	lastEnd time.Duration
+}
+
+// NewSubtitleWriter 创建一个 SubtitleWriter
+func NewSubtitleWriter(w io.Writer, format SubtitleFormat, opts SubtitleWriterOptions) (*SubtitleWriter, error) {
+	switch format {
+	case SubtitleSRT, SubtitleVTT, SubtitleASS, SubtitleLRC:
+	default:
+		return nil, fmt.Errorf("unknown subtitle format %q", format)
+	}
+
+	sw := &SubtitleWriter{
+		w:        w,
+		format:   format,
+		vtt:      newVTTConfig(opts.VTT...),
+		assStyle: DefaultASSStyle(),
+	}
+	if opts.Merge != nil {
+		merger, err := newCueMerger(*opts.Merge)
+		if err != nil {
+			return nil, err
+		}
+		sw.merger = merger
+	}
+	if opts.Readability != nil {
+		if err := opts.Readability.validate(); err != nil {
+			return nil, err
+		}
+		sw.readability = opts.Readability
+	}
+	if opts.ASSStyle != nil {
+		sw.assStyle = *opts.ASSStyle
+	}
+	if sw.assStyle.Name == "" {
+		sw.assStyle.Name = "Default"
+	}
+	return sw, nil
+}
+
+// Feed 处理一个 WordBoundary，SentenceBoundary 被忽略
+func (sw *SubtitleWriter) Feed(chunk TTSChunk) error {
+	if chunk.Type == "SentenceBoundary" {
+		return nil
+	}
+	if chunk.Type != "WordBoundary" {
+		return fmt.Errorf("invalid message type, expected 'WordBoundary'")
+	}
+
+	// 借用 SubMaker 把 WordBoundary 转换为字幕
+	word := NewSubMaker()
+	if err := word.Feed(chunk); err != nil {
+		return err
+	}
+	if len(word.cues) == 0 {
+		return nil
+	}
+	cue := word.cues[0]
+
+	if sw.current != nil && sw.merger != nil {
+		if merged, ok := sw.merger.merge(*sw.current, cue); ok {
+			sw.current = &merged
+			return nil
+		}
+	}
+	if sw.current != nil {
+		if err := sw.writeCue(*sw.current, &cue); err != nil {
+			return err
+		}
+	}
+	sw.current = &cue
+	return nil
+}
+
+// Flush 写入最后一条字幕，在合成结束时调用
+func (sw *SubtitleWriter) Flush() error {
+	if sw.current != nil {
+		if err := sw.writeCue(*sw.current, nil); err != nil {
+			return err
+		}
+		sw.current = nil
+	}
+
+	var b strings.Builder
+	sw.writeHeader(&b)
+	if sw.format == SubtitleLRC && sw.index > 0 {
+		writeLRCEnd(&b, sw.lastEnd)
+	}
+	return sw.write(&b)
+}
+
+// writeCue 完成并写入一条字幕，next 是下一条字幕，用于可读性规则
+func (sw *SubtitleWriter) writeCue(cue SubCue, next *SubCue) error {
+	if sw.merger != nil {
+		cue = sw.merger.finish(cue)
+	}
+	if sw.readability != nil {
+		cue.End = sw.readability.end(cue, next)
+	}
+	// 跳过空文本的字幕
+	if cue.Text == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	sw.writeHeader(&b)
+	sw.index++
+	cue.Index = sw.index
+	switch sw.format {
+	case SubtitleSRT:
+		writeSRTCue(&b, sw.index, cue)
+	case SubtitleVTT:
+		sw.vtt.writeCue(&b, sw.index, cue)
+	case SubtitleASS:
+		writeASSDialogue(&b, sw.assStyle.Name, cue)
+	case SubtitleLRC:
+		(&lrcConfig{}).writeLine(&b, cue)
+	}
+	sw.lastEnd = cue.End
+	return sw.write(&b)
+}
+
+// writeHeader 在第一次写入时加上文件头
+func (sw *SubtitleWriter) writeHeader(b *strings.Builder) {
+	if sw.started {
+		return
+	}
+	sw.started = true
+	switch sw.format {
+	case SubtitleVTT:
+		b.WriteString("WEBVTT\n\n")
+	case SubtitleASS:
+		writeASSHeader(b, sw.assStyle)
+	}
+}
+
+// write 把 b 写入 io.Writer
+func (sw *SubtitleWriter) write(b *strings.Builder) error {
+	if b.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(sw.w, b.String())
+	return err
+}
@@ -0,0 +1,115 @@
+package edge_tts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSubtitleWriter 测试边收边写的字幕与一次生成的字幕相同
+func TestSubtitleWriter(t *testing.T) {
+	words := []string{"The", "quick", "brown", "fox.", "It", "jumps", "over", "the", "lazy", "dog."}
+	merge := MergeOptions{MaxChars: 16, SentenceBreak: true}
+	readability := DefaultReadabilityOptions()
+
+	for _, format := range []SubtitleFormat{SubtitleSRT, SubtitleVTT, SubtitleASS, SubtitleLRC} {
+		t.Run(string(format), func(t *testing.T) {
+			sub := newTestSubMaker(t, words...)
+			if err := sub.MergeCuesWith(merge); err != nil {
+				t.Fatalf("MergeCuesWith() error = %v", err)
+			}
+			if err := sub.ApplyReadability(readability); err != nil {
+				t.Fatalf("ApplyReadability() error = %v", err)
+			}
+			// SubtitleWriter 按写入顺序编号
+			sub.Renumber()
+			var want string
+			switch format {
+			case SubtitleSRT:
+				want = sub.GetSRT()
+			case SubtitleVTT:
+				want = sub.GetVTT()
+			case SubtitleASS:
+				want = sub.GetASS(DefaultASSStyle())
+			case SubtitleLRC:
+				want = sub.GetLRC()
+			}
+
+			var b strings.Builder
+			w, err := NewSubtitleWriter(&b, format, SubtitleWriterOptions{Merge: &merge, Readability: &readability})
+			if err != nil {
+				t.Fatalf("NewSubtitleWriter() error = %v", err)
+			}
+			for i, word := range words {
+				err := w.Feed(TTSChunk{
+					Type:     "WordBoundary",
+					Offset:   float64(i) * 2400000,
+					Duration: 2000000,
+					Text:     word,
+				})
+				if err != nil {
+					t.Fatalf("Feed() error = %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if got := b.String(); got != want {
+				t.Errorf("SubtitleWriter wrote\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestSubtitleWriterStreaming 测试字幕在完成时立即写入，最后一条在 Flush 时写入
+func TestSubtitleWriterStreaming(t *testing.T) {
+	var b strings.Builder
+	w, err := NewSubtitleWriter(&b, SubtitleVTT, SubtitleWriterOptions{Merge: &MergeOptions{MaxWords: 2}})
+	if err != nil {
+		t.Fatalf("NewSubtitleWriter() error = %v", err)
+	}
+	feed := func(i int, text string) {
+		t.Helper()
+		err := w.Feed(TTSChunk{Type: "WordBoundary", Offset: float64(i) * 10000000, Duration: 5000000, Text: text})
+		if err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+	}
+
+	feed(0, "one")
+	feed(1, "two")
+	if b.Len() != 0 {
+		t.Errorf("wrote %q before the first cue was complete", b.String())
+	}
+	feed(2, "three")
+	if want := "WEBVTT\n\n1\n00:00:00.000 --> 00:00:01.500\none two\n\n"; b.String() != want {
+		t.Errorf("after the third word got %q, want %q", b.String(), want)
+	}
+	if err := w.Feed(TTSChunk{Type: "SentenceBoundary", Offset: 0, Duration: float64(time.Second / 100), Text: "one two three"}); err != nil {
+		t.Fatalf("Feed(SentenceBoundary) error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if want := "2\n00:00:02.000 --> 00:00:02.500\nthree\n\n"; !strings.HasSuffix(b.String(), want) {
+		t.Errorf("after Flush() got %q, want suffix %q", b.String(), want)
+	}
+}
+
+// TestSubtitleWriterEmpty 测试没有字幕时仍然写入文件头
+func TestSubtitleWriterEmpty(t *testing.T) {
+	var b strings.Builder
+	w, err := NewSubtitleWriter(&b, SubtitleVTT, SubtitleWriterOptions{})
+	if err != nil {
+		t.Fatalf("NewSubtitleWriter() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := b.String(); got != "WEBVTT\n\n" {
+		t.Errorf("got %q, want the WEBVTT header", got)
+	}
+	if _, err := NewSubtitleWriter(&b, "txt", SubtitleWriterOptions{}); err == nil {
+		t.Error("NewSubtitleWriter() with unknown format, want error")
+	}
+}
@@ -0,0 +1,217 @@
+package edge_tts
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 边界类型
+const (
+	BoundaryWord     = "word"
+	BoundarySentence = "sentence"
+)
+
+// Timing 是一个单词或句子边界的时间，时间单位为毫秒
+type Timing struct {
+	Type     string `json:"type"` // "word" 或 "sentence"
+	Text     string `json:"text"`
+	Start    int64  `json:"start"`
+	End      int64  `json:"end"`
+	Duration int64  `json:"duration"`
+	// Offset 是 Text 在去掉 SSML 标记后的输入文本中的字符（rune）偏移，
+	// 找不到时为 -1
+	Offset int `json:"offset"`
+}
+
+// boundary 是 Feed 收到的原始边界，不受 MergeCues 影响
+type boundary struct {
+	kind  string
+	start time.Duration
+	end   time.Duration
+	text  string
+	// textOffset 和 textLength 是服务报告的边界在原文中的字符位置，
+	// textLength 为 0 时没有报告
+	textOffset int
+	textLength int
+}
+
+// newBoundary 把 WordBoundary 或 SentenceBoundary 转换为 boundary
+func newBoundary(chunk TTSChunk) boundary {
+	// 注意时间单位转换为微秒
+	b := boundary{
+		kind:  BoundaryWord,
+		start: time.Duration(float64(chunk.Offset/10)) * time.Microsecond,
+		end:   time.Duration(float64((chunk.Offset+chunk.Duration)/10)) * time.Microsecond,
+		text:  chunk.Text,
+	}
+	if chunk.Type == "SentenceBoundary" {
+		b.kind = BoundarySentence
+	}
+	if chunk.TextOffset >= 0 && chunk.TextLength > 0 {
+		b.textOffset, b.textLength = chunk.TextOffset, chunk.TextLength
+	}
+	return b
+}
+
+// Timings 返回所有单词和句子边界的时间，input 是合成时的输入文本，
+// 用于计算每个边界的字符偏移。input 中的 SSML 标记会先去掉，
+// 偏移是朗读的文本中的位置
+func (s *SubMaker) Timings(input string) []Timing {
+	timings := make([]Timing, 0, len(s.boundaries))
+	locator := newTimingLocator(input)
+	for _, b := range s.boundaries {
+		timings = append(timings, locator.timing(b))
+	}
+	return timings
+}
+
+// timingLocator 按顺序在去掉标记的输入文本中查找边界，
+// 单词和句子各自记录查找的位置
+type timingLocator struct {
+	input   string
+	cursors map[string]int
+}
+
+// newTimingLocator 创建在 input 中查找边界的 timingLocator
+func newTimingLocator(input string) *timingLocator {
+	return &timingLocator{input: plainText(input), cursors: map[string]int{}}
+}
+
+// timing 返回边界 b 的时间和它在输入中的字符偏移。优先使用服务报告的位置，
+// 没有报告或与输入对不上时按顺序查找
+func (l *timingLocator) timing(b boundary) Timing {
+	cursor := l.cursors[b.kind]
+	offset := -1
+	if _, end, ok := reportedSpan(l.input, b.text, b.textOffset, b.textLength, cursor); ok {
+		offset, l.cursors[b.kind] = b.textOffset, end
+	} else if found, next := findBoundary(l.input, b.text, cursor); found >= 0 {
+		offset, l.cursors[b.kind] = found, next
+	}
+	return Timing{
+		Type:     b.kind,
+		Text:     b.text,
+		Start:    b.start.Milliseconds(),
+		End:      b.end.Milliseconds(),
+		Duration: (b.end - b.start).Milliseconds(),
+		Offset:   offset,
+	}
+}
+
+// findBoundary 从字节位置 from 开始在 input 中查找整词 text（先精确匹配，
+// 再不区分大小写），返回它的字符偏移和结束的字节位置，找不到时返回 -1。
+// 与前后的字母或数字相连的位置不算，例如 "ten" 不会匹配 "often"
+func findBoundary(input, text string, from int) (int, int) {
+	if text == "" {
+		return -1, from
+	}
+	start := indexWord(input, text, from, strings.Index)
+	if start < 0 {
+		start = indexWord(input, text, from, indexFold)
+	}
+	if start < 0 {
+		return -1, from
+	}
+	return utf8.RuneCountInString(input[:start]), start + len(text)
+}
+
+// indexWord 用 index 从字节位置 from 开始查找前后不与其他单词字符相连的
+// text，返回字节位置，找不到时返回 -1
+func indexWord(input, text string, from int, index func(s, substr string) int) int {
+	for from < len(input) {
+		i := index(input[from:], text)
+		if i < 0 {
+			return -1
+		}
+		start := from + i
+		if isWordBoundary(input, start, start+len(text)) {
+			return start
+		}
+		_, size := utf8.DecodeRuneInString(input[start:])
+		from = start + size
+	}
+	return -1
+}
+
+// indexFold 是不区分大小写的 strings.Index
+func indexFold(s, substr string) int {
+	for i := range s {
+		if len(s)-i < len(substr) {
+			break
+		}
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetJSON 生成 JSON 数组格式的边界时间
+func (s *SubMaker) GetJSON(input string) (string, error) {
+	data, err := json.MarshalIndent(s.Timings(input), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// WriteJSONLines 以 JSON Lines 格式写入边界时间，每行一个边界
+func (s *SubMaker) WriteJSONLines(w io.Writer, input string) error {
+	enc := json.NewEncoder(w)
+	for _, timing := range s.Timings(input) {
+		if err := enc.Encode(timing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TimingWriter 在收到边界时把它的时间写成一行 JSON，格式与 WriteJSONLines
+// 相同，用于在合成过程中读取时间
+type TimingWriter struct {
+	enc     *json.Encoder
+	locator *timingLocator
+}
+
+// NewTimingWriter 创建一个 TimingWriter，input 是合成时的输入文本，
+// 用于计算每个边界的字符偏移
+func NewTimingWriter(w io.Writer, input string) *TimingWriter {
+	return &TimingWriter{enc: json.NewEncoder(w), locator: newTimingLocator(input)}
+}
+
+// Feed 写入一个 WordBoundary 或 SentenceBoundary 的时间
+func (w *TimingWriter) Feed(chunk TTSChunk) error {
+	if chunk.Type != "WordBoundary" && chunk.Type != "SentenceBoundary" {
+		return fmt.Errorf("invalid message type, expected 'WordBoundary' or 'SentenceBoundary'")
+	}
+	if chunk.Text == "" {
+		return nil
+	}
+	return w.enc.Encode(w.locator.timing(newBoundary(chunk)))
+}
+
+// isJSONLines 判断时间文件是否使用 JSON Lines 格式：.jsonl 和 .ndjson
+func isJSONLines(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+// writeTimings 根据扩展名写入边界时间：.jsonl 和 .ndjson 为 JSON Lines，其他为 JSON
+func (s *SubMaker) writeTimings(w io.Writer, path, input string) error {
+	if isJSONLines(path) {
+		return s.WriteJSONLines(w, input)
+	}
+	data, err := s.GetJSON(input)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, data)
+	return err
+}
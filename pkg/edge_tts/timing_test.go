@@ -0,0 +1,161 @@
+package edge_tts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestTimings 测试导出单词和句子边界的时间
+func TestTimings(t *testing.T) {
+	input := "你好, world! World 10kg."
+	sub := NewSubMaker()
+	for _, chunk := range []TTSChunk{
+		{Type: "SentenceBoundary", Offset: 0, Duration: 8000000, Text: "你好, world!"},
+		{Type: "WordBoundary", Offset: 0, Duration: 3000000, Text: "你好"},
+		{Type: "WordBoundary", Offset: 4000000, Duration: 4000000, Text: "world"},
+		{Type: "WordBoundary", Offset: 9000000, Duration: 2000000, Text: "world"},
+		{Type: "WordBoundary", Offset: 12000000, Duration: 2000000, Text: "ten"},
+	} {
+		if err := sub.Feed(chunk); err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+	}
+	if err := sub.Feed(TTSChunk{Type: "audio"}); err == nil {
+		t.Error("Feed() should reject audio chunks")
+	}
+
+	// 句子边界不生成字幕
+	if len(sub.cues) != 4 {
+		t.Errorf("len(cues) = %d, want 4", len(sub.cues))
+	}
+
+	want := []Timing{
+		{Type: BoundarySentence, Text: "你好, world!", Start: 0, End: 800, Duration: 800, Offset: 0},
+		{Type: BoundaryWord, Text: "你好", Start: 0, End: 300, Duration: 300, Offset: 0},
+		{Type: BoundaryWord, Text: "world", Start: 400, End: 800, Duration: 400, Offset: 4},
+		{Type: BoundaryWord, Text: "world", Start: 900, End: 1100, Duration: 200, Offset: 11},
+		{Type: BoundaryWord, Text: "ten", Start: 1200, End: 1400, Duration: 200, Offset: -1},
+	}
+	got := sub.Timings(input)
+	if len(got) != len(want) {
+		t.Fatalf("Timings() = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Timings()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	var lines bytes.Buffer
+	if err := sub.writeTimings(&lines, "out.jsonl", input); err != nil {
+		t.Fatalf("writeTimings() error = %v", err)
+	}
+	if first, _, _ := strings.Cut(lines.String(), "\n"); first != `{"type":"sentence","text":"你好, world!","start":0,"end":800,"duration":800,"offset":0}` {
+		t.Errorf("JSON Lines = %q", lines.String())
+	}
+
+	data, err := sub.GetJSON(input)
+	if err != nil {
+		t.Fatalf("GetJSON() error = %v", err)
+	}
+	if !strings.HasPrefix(data, "[\n  {\n    \"type\": \"sentence\",") || !strings.Contains(data, `"offset": -1`) {
+		t.Errorf("GetJSON() = %s", data)
+	}
+}
+
+// TestTimingsSSML 测试输入中的 SSML 标记不参与偏移计算，标记里的属性不会被当作单词
+func TestTimingsSSML(t *testing.T) {
+	input := "Take <break time='500ms'/>your <emphasis level='strong'>time</emphasis>, &amp; level up, strong one."
+	sub := newTestSubMaker(t, "Take", "your", "time", "level", "strong")
+
+	// 去掉标记后为 "Take your time, & level up, strong one."
+	want := []int{0, 5, 10, 18, 28}
+	got := sub.Timings(input)
+	if len(got) != len(want) {
+		t.Fatalf("Timings() = %+v", got)
+	}
+	for i, offset := range want {
+		if got[i].Offset != offset {
+			t.Errorf("Timings()[%d] %q offset = %d, want %d", i, got[i].Text, got[i].Offset, offset)
+		}
+	}
+}
+
+// TestTimingsNormalized 测试规范化改写的数字：使用服务报告的位置，
+// 没有报告时只匹配整词，"ten" 不会匹配 "often" 里的字母
+func TestTimingsNormalized(t *testing.T) {
+	input := "I have 10 cats and often dogs"
+	words := []string{"I", "have", "ten", "cats", "and", "often", "dogs"}
+	// 流中报告的位置已经换算到原文，规范化改写的 "ten" 没有位置
+	offsets := []int{0, 2, -1, 10, 15, 19, 25}
+	want := []int{0, 2, -1, 10, 15, 19, 25}
+
+	for _, reported := range []bool{true, false} {
+		sub := NewSubMaker()
+		for i, word := range words {
+			chunk := TTSChunk{Type: "WordBoundary", Offset: float64(i) * 5000000, Duration: 4000000, Text: word, TextOffset: -1}
+			if reported && offsets[i] >= 0 {
+				chunk.TextOffset, chunk.TextLength = offsets[i], len(word)
+			}
+			if err := sub.Feed(chunk); err != nil {
+				t.Fatalf("Feed() error = %v", err)
+			}
+		}
+		got := sub.Timings(input)
+		for i, offset := range want {
+			if got[i].Offset != offset {
+				t.Errorf("reported = %v: Timings()[%d] %q offset = %d, want %d", reported, i, got[i].Text, got[i].Offset, offset)
+			}
+		}
+	}
+
+	// 报告的位置优先于查找：重复的词使用报告的那一个
+	sub := NewSubMaker()
+	sub.Feed(TTSChunk{Type: "WordBoundary", Duration: 4000000, Text: "dogs", TextOffset: 30, TextLength: 4})
+	if got := sub.Timings("dogs and dogs I have 10 cats, dogs")[0].Offset; got != 30 {
+		t.Errorf("reported offset = %d, want 30", got)
+	}
+}
+
+// TestTimingWriter 测试 TimingWriter 每收到一个边界就写入一行
+func TestTimingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTimingWriter(&buf, "<emphasis level='strong'>Hello</emphasis>, strong world!")
+	chunks := []TTSChunk{
+		{Type: "SentenceBoundary", Offset: 0, Duration: 9000000, Text: "Hello, strong world!"},
+		{Type: "WordBoundary", Offset: 0, Duration: 3000000, Text: "Hello"},
+		{Type: "WordBoundary", Offset: 4000000, Duration: 2000000, Text: ""},
+		{Type: "WordBoundary", Offset: 4000000, Duration: 2000000, Text: "strong"},
+	}
+	wantLines := []int{1, 2, 2, 3}
+	for i, chunk := range chunks {
+		if err := w.Feed(chunk); err != nil {
+			t.Fatalf("Feed() error = %v", err)
+		}
+		if got := strings.Count(buf.String(), "\n"); got != wantLines[i] {
+			t.Fatalf("after chunk %d got %d lines, want %d", i, got, wantLines[i])
+		}
+	}
+	if err := w.Feed(TTSChunk{Type: "audio"}); err == nil {
+		t.Error("Feed() should reject audio chunks")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[2] != `{"type":"word","text":"strong","start":400,"end":600,"duration":200,"offset":7}` {
+		t.Errorf("last line = %s", lines[2])
+	}
+
+	// 与 WriteJSONLines 的结果相同
+	sub := NewSubMaker()
+	for _, chunk := range chunks {
+		sub.Feed(chunk)
+	}
+	var all bytes.Buffer
+	if err := sub.WriteJSONLines(&all, "<emphasis level='strong'>Hello</emphasis>, strong world!"); err != nil {
+		t.Fatal(err)
+	}
+	if all.String() != buf.String() {
+		t.Errorf("WriteJSONLines() = %s, want %s", all.String(), buf.String())
+	}
+}
@@ -1,13 +1,26 @@
 package edge_tts
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 // Error type definitions
 var (
 	ErrUnknownResponse    = errors.New("unknown response from server")
 	ErrUnexpectedResponse = errors.New("unexpected response from server")
 	ErrNoAudioReceived    = errors.New("no audio received from server")
+	ErrVoiceNotFound      = errors.New("voice not found")
 	ErrWebSocketError     = errors.New("websocket error")
+	// ErrVoiceCacheNotSaved wraps why a downloaded voice list could not be
+	// written to the cache, the list itself is still returned
+	ErrVoiceCacheNotSaved = errors.New("voice list not saved to cache")
 )
 
 // TTSConfig defines the text-to-speech configuration
@@ -17,16 +30,85 @@ type TTSConfig struct {
 	Volume string
 	Pitch  string
 	Text   string
+
+	// Style is an optional speaking style (see Voice.StyleList), written
+	// as <mstts:express-as style>
+	Style string
+
+	// Locale is written as the root xml:lang of the SSML document.
+	// When empty it is derived from Voice, see LocaleFromVoice.
+	Locale string
+	// LangSpans marks byte ranges of Text that are spoken in another
+	// language, which is how multilingual voices pick the pronunciation.
+	LangSpans []LangSpan
+	// Normalize runs the built-in and registered normalizers of the
+	// document locale on Text, see NormalizerForLocale
+	Normalize bool
+	// Normalizers run on Text after the locale normalizers
+	Normalizers []TextNormalizer
+	// Lexicons rewrite matching words into <sub> or <phoneme> elements
+	// before normalization, only lexicons matching the document locale are
+	// used. A word rewritten by an earlier lexicon is not matched again.
+	Lexicons []*Lexicon
+
+	// SentenceBoundaries asks the service for SentenceBoundary metadata
+	// in addition to WordBoundary
+	SentenceBoundaries bool
+
+	// SubtitleMerge groups the word cues written by Save into subtitles,
+	// nil writes one cue per word
+	SubtitleMerge *MergeOptions
+	// SubtitleReadability extends short cues written by Save, see
+	// ApplyReadability
+	SubtitleReadability *ReadabilityOptions
+	// AlignSubtitles makes Save show the input text, with its punctuation
+	// and casing, instead of the bare spoken words, see AlignText
+	AlignSubtitles bool
+
+	// TargetDuration makes Stream adjust the rate until the audio lasts
+	// this long, 0 keeps Rate
+	TargetDuration time.Duration
+	// FitTolerance is how far the audio may be from TargetDuration,
+	// DefaultFitTolerance when 0
+	FitTolerance time.Duration
+
+	// ClientProfile and TokenProvider replace the ones from
+	// LoadClientConfig when not nil
+	ClientProfile *ClientProfile
+	TokenProvider TokenProvider
+	// Clock and IDs replace the system clock and random identifiers when
+	// not nil, see WithClock and WithIDGenerator
+	Clock Clock
+	IDs   IDGenerator
+
+	// MUID is sent as the muid cookie, or read from MUIDFile, see
+	// WithMUID and WithMUIDFile. A random one is used when both are empty.
+	MUID     string
+	MUIDFile string
+	// CookieJar keeps the cookies of the connections, see WithCookieJar
+	CookieJar http.CookieJar
+}
+
+// LangSpan marks Text[Start:End] as being in the language Lang
+type LangSpan struct {
+	Start int
+	End   int
+	Lang  string
 }
 
 // TTSChunk represents an audio data chunk or metadata
 type TTSChunk struct {
-	Type     string                 // "audio" or "WordBoundary"
-	Data     []byte                 // Audio data
-	Offset   float64                // Only used for WordBoundary
-	Duration float64                // Only used for WordBoundary
-	Text     string                 // Only used for WordBoundary
-	Metadata map[string]interface{} // Other metadata
+	Type     string  // "audio", "WordBoundary" or "SentenceBoundary"
+	Data     []byte  // Audio data
+	Offset   float64 // Only used for boundaries
+	Duration float64 // Only used for boundaries
+	Text     string  // Only used for boundaries
+	// TextOffset is the character offset of Text in the input text without
+	// its SSML markup. It is -1 when the service does not report it or the
+	// word is not in the input, like a number spelled out by normalization.
+	TextOffset int
+	TextLength int                    // Length of Text in characters as reported by the service
+	Metadata   map[string]interface{} // Other metadata
 }
 
 // CommunicateState represents the communication state
@@ -56,6 +138,21 @@ type Voice struct {
 	SampleRate int      `json:"SampleRate"`
 	StyleList  []string `json:"StyleList"`
 	VoiceTag   VoiceTag `json:"VoiceTag"`
+
+	// FriendlyName is the display name, e.g.
+	// "Microsoft Xiaoxiao Online (Natural) - Chinese (Mainland)"
+	FriendlyName string `json:"FriendlyName,omitempty"`
+	// Status is "GA" for generally available voices, or "Preview"
+	Status string `json:"Status,omitempty"`
+	// SuggestedCodec is the output format the voice is meant for,
+	// e.g. "audio-24khz-48kbitrate-mono-mp3"
+	SuggestedCodec string `json:"SuggestedCodec,omitempty"`
+	// SecondaryLocaleList are the other locales a multilingual voice speaks
+	SecondaryLocaleList []string `json:"SecondaryLocaleList,omitempty"`
+
+	// Extra keeps the fields of the voice list this version does not know,
+	// so they are not lost when a voice is written back as JSON
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // NewTTSConfig creates a new TTSConfig
@@ -71,6 +168,55 @@ func NewTTSConfig(text, voice string) *TTSConfig {
 
 // Validate validates the TTSConfig parameters
 func (c *TTSConfig) Validate() error {
-	// TODO: Implement parameter validation
+	spans := sortedLangSpans(c.LangSpans)
+	end := 0
+	for _, span := range spans {
+		if span.Lang == "" {
+			return fmt.Errorf("lang span [%d:%d] has no language", span.Start, span.End)
+		}
+		if span.Start < end || span.End < span.Start || span.End > len(c.Text) {
+			return fmt.Errorf("invalid lang span [%d:%d] for text of length %d", span.Start, span.End, len(c.Text))
+		}
+		if !runeBoundary(c.Text, span.Start) || !runeBoundary(c.Text, span.End) {
+			return fmt.Errorf("lang span [%d:%d] splits a character", span.Start, span.End)
+		}
+		end = span.End
+	}
+	if c.TargetDuration < 0 || c.FitTolerance < 0 {
+		return fmt.Errorf("invalid target duration %v with tolerance %v", c.TargetDuration, c.FitTolerance)
+	}
 	return nil
 }
+
+// LocaleFromVoice returns the locale part of a voice name. It accepts both
+// short names ("zh-CN-XiaoxiaoNeural", "zh-CN-liaoning-XiaobeiNeural") and
+// full names ("Microsoft Server Speech Text to Speech Voice (en-US, EmmaNeural)").
+// An empty string is returned when no locale can be found.
+func LocaleFromVoice(voice string) string {
+	voice = strings.TrimSpace(voice)
+	if open := strings.Index(voice, "("); open >= 0 {
+		if comma := strings.Index(voice[open:], ","); comma > 0 {
+			return strings.TrimSpace(voice[open+1 : open+comma])
+		}
+	}
+	i := strings.LastIndex(voice, "-")
+	if i <= 0 {
+		return ""
+	}
+	return voice[:i]
+}
+
+// runeBoundary reports whether i is the byte offset of a rune in s or its end
+func runeBoundary(s string, i int) bool {
+	return i == len(s) || utf8.RuneStart(s[i])
+}
+
+// sortedLangSpans returns a copy of spans ordered by start offset
+func sortedLangSpans(spans []LangSpan) []LangSpan {
+	sorted := make([]LangSpan, len(spans))
+	copy(sorted, spans)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	return sorted
+}
@@ -0,0 +1,130 @@
+package edge_tts
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// voiceFields are the JSON keys of the Voice fields, the other keys of a
+// voice go to Extra
+var voiceFields = func() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(Voice{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// voiceJSON has the fields of Voice without its JSON methods
+type voiceJSON Voice
+
+// UnmarshalJSON reads a voice and keeps unknown fields in Extra
+func (v *Voice) UnmarshalJSON(data []byte) error {
+	var known voiceJSON
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	known.Extra = nil
+	for key, value := range all {
+		if voiceFields[key] {
+			continue
+		}
+		if known.Extra == nil {
+			known.Extra = map[string]json.RawMessage{}
+		}
+		known.Extra[key] = value
+	}
+	*v = Voice(known)
+	return nil
+}
+
+// MarshalJSON writes a voice together with the fields in Extra
+func (v Voice) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(voiceJSON(v))
+	if err != nil || len(v.Extra) == 0 {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for key, value := range v.Extra {
+		if !voiceFields[key] {
+			all[key] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+// Language returns the language of the voice locale, e.g. "en" for "en-US"
+func (v Voice) Language() string {
+	language, _, _, _ := splitLocale(v.Locale)
+	return language
+}
+
+// Region returns the region of the voice locale, e.g. "US" for "en-US" and
+// "CN" for "zh-CN-liaoning"
+func (v Voice) Region() string {
+	_, _, region, _ := splitLocale(v.Locale)
+	return region
+}
+
+// IsMultilingual reports whether the voice speaks several languages, such
+// as "en-US-AvaMultilingualNeural"
+func (v Voice) IsMultilingual() bool {
+	return strings.Contains(v.ShortName, "Multilingual") || len(v.SecondaryLocaleList) > 0
+}
+
+// SupportsStyle reports whether style is in the StyleList of the voice
+func (v Voice) SupportsStyle(style string) bool {
+	return containsFold(v.StyleList, style)
+}
+
+// LocaleName returns the English name of the voice locale, e.g.
+// "German (Austria)", see LocaleDisplayName
+func (v Voice) LocaleName() string {
+	return LocaleDisplayName(v.Locale)
+}
+
+// NativeLocaleName returns the name of the voice locale in its own
+// language, e.g. "Deutsch (Österreich)", see LocaleNativeName
+func (v Voice) NativeLocaleName() string {
+	return LocaleNativeName(v.Locale)
+}
+
+// splitLocale splits a locale such as "iu-Latn-CA" or "zh-CN-liaoning"
+// into its language, script, region and variant
+func splitLocale(locale string) (language, script, region, variant string) {
+	parts := strings.Split(locale, "-")
+	language = strings.ToLower(parts[0])
+	for _, part := range parts[1:] {
+		switch {
+		case len(part) == 4 && script == "" && region == "":
+			script = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		case (len(part) == 2 || len(part) == 3 && isDigits(part)) && region == "":
+			region = strings.ToUpper(part)
+		default:
+			variant = strings.ToLower(part)
+		}
+	}
+	return language, script, region, variant
+}
+
+// isDigits reports whether s only has ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
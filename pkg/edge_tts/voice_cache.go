@@ -0,0 +1,235 @@
+package edge_tts
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// embeddedVoiceList is a snapshot of the voice list, refresh it with
+// "edge-tts voices update -o pkg/edge_tts/voices.json"
+//
+//go:embed voices.json
+var embeddedVoiceList []byte
+
+// EmbeddedVoices returns the voice list shipped with the package, used when
+// the service cannot be reached. It may miss voices added since, and it
+// only has names, genders and locales: filters on styles, categories and
+// personalities fail VoiceFilter.Check on it.
+func EmbeddedVoices() []Voice {
+	var voices []Voice
+	if err := json.Unmarshal(embeddedVoiceList, &voices); err != nil {
+		panic("edge_tts: invalid embedded voice list: " + err.Error())
+	}
+	return voices
+}
+
+// DefaultVoiceCacheTTL is how long a cached voice list is used before it is
+// checked again
+const DefaultVoiceCacheTTL = 24 * time.Hour
+
+// VoiceSource tells where a voice list came from
+type VoiceSource string
+
+const (
+	VoiceSourceNetwork  VoiceSource = "network"
+	VoiceSourceCache    VoiceSource = "cache"
+	VoiceSourceEmbedded VoiceSource = "embedded"
+)
+
+// VoiceCache keeps the live voice list on disk, so lookups work offline and
+// do not download the list every time
+type VoiceCache struct {
+	// Path is the cache file
+	Path string
+	// TTL is how long the cached list is used before it is refreshed
+	TTL time.Duration
+	// Proxy is used for requests, HTTP_PROXY or HTTPS_PROXY when empty
+	Proxy string
+	// Profile and TokenProvider replace the ones from LoadClientConfig
+	// when not nil
+	Profile       *ClientProfile
+	TokenProvider TokenProvider
+
+	url string
+}
+
+// voiceCacheFile is the content of the cache file
+type voiceCacheFile struct {
+	Fetched time.Time `json:"fetched"`
+	ETag    string    `json:"etag,omitempty"`
+	Voices  []Voice   `json:"voices"`
+}
+
+// DefaultVoiceCachePath returns the cache file in the user cache directory,
+// e.g. ~/.cache/edge-tts/voices.json on Linux
+func DefaultVoiceCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "edge-tts", "voices.json"), nil
+}
+
+// NewVoiceCache creates a VoiceCache at DefaultVoiceCachePath with the default TTL
+func NewVoiceCache() (*VoiceCache, error) {
+	path, err := DefaultVoiceCachePath()
+	if err != nil {
+		return nil, err
+	}
+	return &VoiceCache{Path: path, TTL: DefaultVoiceCacheTTL}, nil
+}
+
+// Voices returns the cached list while it is younger than TTL, and
+// otherwise refreshes it. When the service cannot be reached an outdated
+// cached list is used, and without one the embedded list. A downloaded
+// list is returned even when it cannot be saved.
+func (c *VoiceCache) Voices(ctx context.Context) ([]Voice, VoiceSource) {
+	cached, err := c.load()
+	if err == nil && time.Since(cached.Fetched) < c.TTL {
+		return cached.Voices, VoiceSourceCache
+	}
+	if voices, modified, err := c.refresh(ctx, cached); err == nil || errors.Is(err, ErrVoiceCacheNotSaved) {
+		// Not modified, the cached list is still current
+		if !modified {
+			return voices, VoiceSourceCache
+		}
+		return voices, VoiceSourceNetwork
+	}
+	if cached != nil {
+		return cached.Voices, VoiceSourceCache
+	}
+	return EmbeddedVoices(), VoiceSourceEmbedded
+}
+
+// Update refreshes the cache regardless of its age. The request is
+// conditional, modified is false when the list has not changed. When the
+// list cannot be saved the error wraps ErrVoiceCacheNotSaved and voices is
+// still the downloaded list.
+func (c *VoiceCache) Update(ctx context.Context) (voices []Voice, modified bool, err error) {
+	cached, _ := c.load()
+	return c.refresh(ctx, cached)
+}
+
+// refresh downloads the list, or only checks its ETag if cached is not nil,
+// and saves it. A failed save is reported with ErrVoiceCacheNotSaved along
+// with the list.
+func (c *VoiceCache) refresh(ctx context.Context, cached *voiceCacheFile) ([]Voice, bool, error) {
+	proxy := c.Proxy
+	if proxy == "" {
+		proxy = os.Getenv("HTTP_PROXY")
+	}
+	if proxy == "" {
+		proxy = os.Getenv("HTTPS_PROXY")
+	}
+	client, err := voiceListClient(proxy)
+	if err != nil {
+		return nil, false, err
+	}
+	config := TTSConfig{ClientProfile: c.Profile, TokenProvider: c.TokenProvider}
+	profile, tokens, err := config.clientConfig()
+	if err != nil {
+		return nil, false, err
+	}
+	listURL := c.url
+	if listURL == "" {
+		listURL = profile.VoiceListURL()
+	}
+
+	etag := ""
+	if cached != nil {
+		etag = cached.ETag
+	}
+	voices, etag, err := fetchVoices(ctx, client, listURL, etag, profile, tokens)
+	modified := true
+	if errors.Is(err, errNotModified) {
+		voices, modified = cached.Voices, false
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if err := c.save(&voiceCacheFile{Fetched: time.Now(), ETag: etag, Voices: voices}); err != nil {
+		return voices, modified, fmt.Errorf("%w: %v", ErrVoiceCacheNotSaved, err)
+	}
+	return voices, modified, nil
+}
+
+// load reads the cache file
+func (c *VoiceCache) load() (*voiceCacheFile, error) {
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	var cached voiceCacheFile
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+// save writes the cache file through a temporary file, so readers never see
+// half of it
+func (c *VoiceCache) save(cached *voiceCacheFile) error {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), ".voices-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.Path)
+}
+
+// ReadVoiceFile reads a stored voice list: a JSON array of voices, as
+// written by "edge-tts voices update -o" or "-list-voices -output json", or
+// a VoiceCache file
+func ReadVoiceFile(path string) ([]Voice, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var cached voiceCacheFile
+		if err := json.Unmarshal(data, &cached); err != nil {
+			return nil, fmt.Errorf("parse voice cache %s failed: %w", path, err)
+		}
+		return cached.Voices, nil
+	}
+	voices, err := readVoices(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return voices, nil
+}
+
+// CachedVoices returns the voice list through the default VoiceCache. It
+// uses the embedded list when there is no cache directory and the service
+// cannot be reached.
+func CachedVoices(ctx context.Context) ([]Voice, VoiceSource) {
+	cache, err := NewVoiceCache()
+	if err != nil {
+		if voices, err := ListVoices(ctx); err == nil {
+			return voices, VoiceSourceNetwork
+		}
+		return EmbeddedVoices(), VoiceSourceEmbedded
+	}
+	return cache.Voices(ctx)
+}
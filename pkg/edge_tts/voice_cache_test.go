@@ -0,0 +1,113 @@
+package edge_tts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestEmbeddedVoices 测试内置的声音列表
+func TestEmbeddedVoices(t *testing.T) {
+	voices := EmbeddedVoices()
+	v, err := FindVoice(voices, "zh-CN-XiaoxiaoNeural")
+	if err != nil {
+		t.Fatalf("FindVoice() error = %v", err)
+	}
+	if v.Locale != "zh-CN" || v.Gender != "Female" {
+		t.Errorf("voice = %+v, want a zh-CN female voice", v)
+	}
+}
+
+// TestVoiceCache 测试缓存声音列表和按 ETag 更新
+func TestVoiceCache(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"ShortName":"en-US-AvaNeural","Locale":"en-US","VoiceTag":{"ContentCategories":[" News"]}}]`))
+	}))
+	defer server.Close()
+
+	cache := &VoiceCache{
+		Path: filepath.Join(t.TempDir(), "edge-tts", "voices.json"),
+		TTL:  time.Hour,
+		url:  server.URL + "/voices/list?trustedclienttoken=test",
+	}
+	ctx := context.Background()
+
+	voices, source := cache.Voices(ctx)
+	if source != VoiceSourceNetwork || len(voices) != 1 || voices[0].VoiceTag.ContentCategories[0] != "News" {
+		t.Fatalf("Voices() = %+v, %s, want the downloaded list", voices, source)
+	}
+	if _, source := cache.Voices(ctx); source != VoiceSourceCache || requests != 1 {
+		t.Errorf("second Voices() source = %s after %d requests, want the cache without a request", source, requests)
+	}
+
+	voices, modified, err := cache.Update(ctx)
+	if err != nil || modified || len(voices) != 1 || notModified != 1 {
+		t.Errorf("Update() = %v, %v, %v (%d not modified), want the cached list unchanged", voices, modified, err, notModified)
+	}
+
+	// 缓存过期后服务返回 304 时，列表仍然来自缓存
+	cache.TTL = 0
+	if voices, source := cache.Voices(ctx); source != VoiceSourceCache || len(voices) != 1 || notModified != 2 {
+		t.Errorf("not modified Voices() = %v, %s (%d not modified), want the cache", voices, source, notModified)
+	}
+
+	// 服务不可用时使用过期的缓存，没有缓存时使用内置列表
+	server.Close()
+	if voices, source := cache.Voices(ctx); source != VoiceSourceCache || len(voices) != 1 {
+		t.Errorf("offline Voices() = %v, %s, want the outdated cache", voices, source)
+	}
+	cache.Path = filepath.Join(t.TempDir(), "voices.json")
+	if voices, source := cache.Voices(ctx); source != VoiceSourceEmbedded || len(voices) < 100 {
+		t.Errorf("offline Voices() without cache = %d voices, %s, want the embedded list", len(voices), source)
+	}
+	if _, _, err := cache.Update(ctx); err == nil {
+		t.Error("offline Update() want error")
+	}
+}
+
+// TestVoiceCacheNotSaved 测试缓存目录不可写时仍然返回下载的列表
+func TestVoiceCacheNotSaved(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("HTTPS_PROXY", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"ShortName":"en-US-AvaNeural","Locale":"en-US"}]`))
+	}))
+	defer server.Close()
+
+	// 缓存目录的位置是一个普通文件，无法创建目录
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := &VoiceCache{
+		Path: filepath.Join(file, "edge-tts", "voices.json"),
+		TTL:  time.Hour,
+		url:  server.URL + "/voices/list?trustedclienttoken=test",
+	}
+	ctx := context.Background()
+
+	if voices, source := cache.Voices(ctx); source != VoiceSourceNetwork || len(voices) != 1 {
+		t.Errorf("Voices() = %d voices, %s, want the downloaded list", len(voices), source)
+	}
+	voices, modified, err := cache.Update(ctx)
+	if !errors.Is(err, ErrVoiceCacheNotSaved) || !modified || len(voices) != 1 {
+		t.Errorf("Update() = %v, %v, %v, want the downloaded list and ErrVoiceCacheNotSaved", voices, modified, err)
+	}
+}
@@ -0,0 +1,125 @@
+package edge_tts
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VoiceDiff is the difference between two voice lists, see DiffVoices
+type VoiceDiff struct {
+	// Added are the voices only in the new list
+	Added []Voice `json:"added"`
+	// Removed are the voices only in the old list
+	Removed []Voice `json:"removed"`
+	// Changed are the voices in both lists whose details differ
+	Changed []VoiceChange `json:"changed"`
+}
+
+// VoiceChange lists the fields that changed for one voice
+type VoiceChange struct {
+	ShortName string             `json:"shortName"`
+	Fields    []VoiceFieldChange `json:"fields"`
+}
+
+// VoiceFieldChange is one changed field. Lists are compared regardless of
+// their order and written comma-separated.
+type VoiceFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Empty reports whether the lists are the same
+func (d VoiceDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffVoices compares a stored voice list with a newer one, e.g. from
+// ListVoices. Voices are matched by short name, ignoring case, and each
+// part of the result is sorted by short name.
+func DiffVoices(old, new []Voice) VoiceDiff {
+	oldByName := make(map[string]Voice, len(old))
+	for _, v := range old {
+		oldByName[strings.ToLower(v.ShortName)] = v
+	}
+	newByName := make(map[string]Voice, len(new))
+	for _, v := range new {
+		newByName[strings.ToLower(v.ShortName)] = v
+	}
+
+	diff := VoiceDiff{Added: []Voice{}, Removed: []Voice{}, Changed: []VoiceChange{}}
+	for key, v := range newByName {
+		prev, ok := oldByName[key]
+		if !ok {
+			diff.Added = append(diff.Added, v)
+			continue
+		}
+		if fields := diffVoiceFields(prev, v); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, VoiceChange{ShortName: v.ShortName, Fields: fields})
+		}
+	}
+	for key, v := range oldByName {
+		if _, ok := newByName[key]; !ok {
+			diff.Removed = append(diff.Removed, v)
+		}
+	}
+
+	sortVoices(diff.Added)
+	sortVoices(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].ShortName < diff.Changed[j].ShortName
+	})
+	return diff
+}
+
+// diffVoiceFields returns the fields that differ between two versions of a voice
+func diffVoiceFields(old, new Voice) []VoiceFieldChange {
+	pairs := []struct {
+		field    string
+		old, new string
+	}{
+		{"Name", old.Name, new.Name},
+		{"Gender", old.Gender, new.Gender},
+		{"Locale", old.Locale, new.Locale},
+		{"LocalName", old.LocalName, new.LocalName},
+		{"SampleRate", sampleRateString(old.SampleRate), sampleRateString(new.SampleRate)},
+		{"StyleList", sortedList(old.StyleList), sortedList(new.StyleList)},
+		{"ContentCategories", sortedList(old.VoiceTag.ContentCategories), sortedList(new.VoiceTag.ContentCategories)},
+		{"VoicePersonalities", sortedList(old.VoiceTag.VoicePersonalities), sortedList(new.VoiceTag.VoicePersonalities)},
+		{"FriendlyName", old.FriendlyName, new.FriendlyName},
+		{"Status", old.Status, new.Status},
+		{"SuggestedCodec", old.SuggestedCodec, new.SuggestedCodec},
+		{"SecondaryLocaleList", sortedList(old.SecondaryLocaleList), sortedList(new.SecondaryLocaleList)},
+	}
+
+	var fields []VoiceFieldChange
+	for _, p := range pairs {
+		if p.old != p.new {
+			fields = append(fields, VoiceFieldChange{Field: p.field, Old: p.old, New: p.new})
+		}
+	}
+	return fields
+}
+
+// sortedList joins a list in sorted order, so lists with the same items compare equal
+func sortedList(list []string) string {
+	sorted := append([]string(nil), list...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// sampleRateString formats a sample rate, empty when it is not known
+func sampleRateString(rate int) string {
+	if rate == 0 {
+		return ""
+	}
+	return strconv.Itoa(rate)
+}
+
+// sortVoices sorts voices by short name
+func sortVoices(voices []Voice) {
+	sort.Slice(voices, func(i, j int) bool {
+		return voices[i].ShortName < voices[j].ShortName
+	})
+}
@@ -0,0 +1,78 @@
+package edge_tts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDiffVoices 测试比较新旧声音列表
+func TestDiffVoices(t *testing.T) {
+	old := []Voice{
+		{ShortName: "en-US-AriaNeural", Locale: "en-US", StyleList: []string{"cheerful", "sad"}, Status: "GA"},
+		{ShortName: "en-US-GuyNeural", Locale: "en-US"},
+		{ShortName: "zh-CN-XiaoxiaoNeural", Locale: "zh-CN", StyleList: []string{"sad", "cheerful"},
+			VoiceTag: VoiceTag{ContentCategories: []string{"News"}}},
+		{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN", Status: "Preview"},
+	}
+	new := []Voice{
+		{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN", Status: "GA"},
+		{ShortName: "zh-CN-XiaoxiaoNeural", Locale: "zh-CN", StyleList: []string{"cheerful", "sad"},
+			VoiceTag: VoiceTag{ContentCategories: []string{"News"}}},
+		{ShortName: "en-US-AriaNeural", Locale: "en-US", StyleList: []string{"cheerful"}, Status: "GA"},
+		{ShortName: "en-US-AvaNeural", Locale: "en-US"},
+	}
+
+	diff := DiffVoices(old, new)
+	if got := voiceNames(diff.Added); !reflect.DeepEqual(got, []string{"en-US-AvaNeural"}) {
+		t.Errorf("Added = %v", got)
+	}
+	if got := voiceNames(diff.Removed); !reflect.DeepEqual(got, []string{"en-US-GuyNeural"}) {
+		t.Errorf("Removed = %v", got)
+	}
+	// 风格顺序不同的 Xiaoxiao 不算变化
+	want := []VoiceChange{
+		{ShortName: "en-US-AriaNeural", Fields: []VoiceFieldChange{{Field: "StyleList", Old: "cheerful, sad", New: "cheerful"}}},
+		{ShortName: "zh-CN-YunxiNeural", Fields: []VoiceFieldChange{{Field: "Status", Old: "Preview", New: "GA"}}},
+	}
+	if !reflect.DeepEqual(diff.Changed, want) {
+		t.Errorf("Changed = %+v, want %+v", diff.Changed, want)
+	}
+	if diff.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+	if d := DiffVoices(old, old); !d.Empty() {
+		t.Errorf("DiffVoices(old, old) = %+v, want empty", d)
+	}
+}
+
+// TestReadVoiceFile 测试读取 JSON 数组和缓存文件两种格式
+func TestReadVoiceFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"list.json":  `[{"ShortName": "en-US-AvaNeural", "VoiceTag": {"ContentCategories": [" News "]}}]`,
+		"cache.json": `{"fetched": "2024-01-01T00:00:00Z", "voices": [{"ShortName": "en-US-AvaNeural"}]}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		voices, err := ReadVoiceFile(path)
+		if err != nil {
+			t.Fatalf("ReadVoiceFile(%s) error = %v", name, err)
+		}
+		if got := voiceNames(voices); !reflect.DeepEqual(got, []string{"en-US-AvaNeural"}) {
+			t.Errorf("ReadVoiceFile(%s) = %v", name, got)
+		}
+	}
+
+	voices, _ := ReadVoiceFile(filepath.Join(dir, "list.json"))
+	if got := voices[0].VoiceTag.ContentCategories; !reflect.DeepEqual(got, []string{"News"}) {
+		t.Errorf("ContentCategories = %q, want trimmed", got)
+	}
+	if _, err := ReadVoiceFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("ReadVoiceFile(missing) want error")
+	}
+}
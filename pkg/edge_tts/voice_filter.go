@@ -0,0 +1,302 @@
+package edge_tts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VoiceFilter selects voices from ListVoices. Empty fields match every
+// voice; text is compared case-insensitively.
+type VoiceFilter struct {
+	// Locale is the exact locale, e.g. "en-US"
+	Locale string
+	// Language matches every locale of a language, e.g. "en" matches
+	// "en-US" and "en-GB"
+	Language string
+	// Gender is "Female" or "Male"
+	Gender string
+	// Style only matches voices that support the speaking style, see WithStyle
+	Style string
+	// Multilingual only matches voices that speak several languages
+	Multilingual bool
+	// ContentCategory and Personality match the voice tags, e.g. "News"
+	// and "Friendly"
+	ContentCategory string
+	Personality     string
+}
+
+// ParseVoiceFilter parses a filter written as comma-separated key=value
+// pairs, e.g. "language=en,gender=female,style=cheerful". The keys are
+// locale, language, gender, style, category and personality, and a bare
+// "multilingual".
+func ParseVoiceFilter(s string) (VoiceFilter, error) {
+	var f VoiceFilter
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "locale":
+			f.Locale = value
+		case "language", "lang":
+			f.Language = value
+		case "gender":
+			f.Gender = value
+		case "style":
+			f.Style = value
+		case "multilingual":
+			f.Multilingual = value == "" || strings.EqualFold(value, "true")
+		case "category":
+			f.ContentCategory = value
+		case "personality":
+			f.Personality = value
+		default:
+			return VoiceFilter{}, fmt.Errorf("unknown voice filter %q", key)
+		}
+	}
+	return f, nil
+}
+
+// Match reports whether the voice passes the filter
+func (f VoiceFilter) Match(v Voice) bool {
+	if f.Locale != "" && !strings.EqualFold(v.Locale, f.Locale) {
+		return false
+	}
+	if f.Language != "" && !strings.EqualFold(v.Language(), f.Language) {
+		return false
+	}
+	if f.Gender != "" && !strings.EqualFold(v.Gender, f.Gender) {
+		return false
+	}
+	if f.Style != "" && !v.SupportsStyle(f.Style) {
+		return false
+	}
+	if f.Multilingual && !v.IsMultilingual() {
+		return false
+	}
+	if f.ContentCategory != "" && !containsFold(v.VoiceTag.ContentCategories, f.ContentCategory) {
+		return false
+	}
+	if f.Personality != "" && !containsFold(v.VoiceTag.VoicePersonalities, f.Personality) {
+		return false
+	}
+	return true
+}
+
+// ErrNoVoiceMetadata is returned by VoiceFilter.Check when the filter needs
+// styles, content categories or personalities and the voice list has none,
+// like the embedded list
+var ErrNoVoiceMetadata = errors.New("the voice list has no styles, categories or personalities")
+
+// NeedsMetadata reports whether the filter matches on styles, content
+// categories or personalities, which only the live voice list has
+func (f VoiceFilter) NeedsMetadata() bool {
+	return f.Style != "" || f.ContentCategory != "" || f.Personality != ""
+}
+
+// Check returns ErrNoVoiceMetadata when the filter needs metadata that no
+// voice in voices has, so it would silently match nothing
+func (f VoiceFilter) Check(voices []Voice) error {
+	if !f.NeedsMetadata() {
+		return nil
+	}
+	for _, v := range voices {
+		if len(v.StyleList) > 0 || len(v.VoiceTag.ContentCategories) > 0 || len(v.VoiceTag.VoicePersonalities) > 0 {
+			return nil
+		}
+	}
+	return ErrNoVoiceMetadata
+}
+
+// FilterVoices returns the voices that pass the filter, in their original order
+func FilterVoices(voices []Voice, f VoiceFilter) []Voice {
+	var matched []Voice
+	for _, v := range voices {
+		if f.Match(v) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// VoiceNotFoundError is returned when a voice does not exist. Suggestions
+// holds the closest voice names.
+type VoiceNotFoundError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *VoiceNotFoundError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("voice %q not found", e.Name)
+	}
+	return fmt.Sprintf("voice %q not found, did you mean %s?", e.Name, strings.Join(e.Suggestions, ", "))
+}
+
+// Unwrap makes errors.Is(err, ErrVoiceNotFound) work
+func (e *VoiceNotFoundError) Unwrap() error {
+	return ErrVoiceNotFound
+}
+
+// maxVoiceSuggestions is the number of names suggested by FindVoice
+const maxVoiceSuggestions = 3
+
+// FindVoice returns the voice with the short name, or the full name, given.
+// If there is none the error is a *VoiceNotFoundError with the closest names.
+func FindVoice(voices []Voice, name string) (Voice, error) {
+	for _, v := range voices {
+		if strings.EqualFold(v.ShortName, name) || strings.EqualFold(v.Name, name) {
+			return v, nil
+		}
+	}
+	return Voice{}, &VoiceNotFoundError{Name: name, Suggestions: SuggestVoices(voices, name, maxVoiceSuggestions)}
+}
+
+// defaultVoices are the preferred voices of common locales
+var defaultVoices = map[string]string{
+	"de-DE": "de-DE-KatjaNeural",
+	"en-GB": "en-GB-SoniaNeural",
+	"en-US": "en-US-AvaNeural",
+	"es-ES": "es-ES-ElviraNeural",
+	"fr-FR": "fr-FR-DeniseNeural",
+	"it-IT": "it-IT-ElsaNeural",
+	"ja-JP": "ja-JP-NanamiNeural",
+	"ko-KR": "ko-KR-SunHiNeural",
+	"pt-BR": "pt-BR-FranciscaNeural",
+	"ru-RU": "ru-RU-SvetlanaNeural",
+	"zh-CN": "zh-CN-XiaoxiaoNeural",
+	"zh-HK": "zh-HK-HiuMaanNeural",
+	"zh-TW": "zh-TW-HsiaoChenNeural",
+}
+
+// defaultLocales are the locales used for a bare language
+var defaultLocales = map[string]string{
+	"de": "de-DE",
+	"en": "en-US",
+	"es": "es-ES",
+	"fr": "fr-FR",
+	"it": "it-IT",
+	"ja": "ja-JP",
+	"ko": "ko-KR",
+	"pt": "pt-BR",
+	"ru": "ru-RU",
+	"zh": "zh-CN",
+}
+
+// DefaultVoiceForLocale returns a voice for the locale, e.g. "en-US", or a
+// bare language such as "en". Well-known locales have a preferred voice;
+// otherwise the first voice of the locale in the list is used.
+func DefaultVoiceForLocale(voices []Voice, locale string) (Voice, error) {
+	if !strings.Contains(locale, "-") {
+		language := strings.ToLower(locale)
+		if preferred, ok := defaultLocales[language]; ok {
+			if v, err := DefaultVoiceForLocale(voices, preferred); err == nil {
+				return v, nil
+			}
+		}
+		if matched := FilterVoices(voices, VoiceFilter{Language: language}); len(matched) > 0 {
+			return matched[0], nil
+		}
+		return Voice{}, fmt.Errorf("no voice for language %q: %w", locale, ErrVoiceNotFound)
+	}
+
+	for key, name := range defaultVoices {
+		if strings.EqualFold(key, locale) {
+			if v, err := FindVoice(voices, name); err == nil {
+				return v, nil
+			}
+		}
+	}
+	if matched := FilterVoices(voices, VoiceFilter{Locale: locale}); len(matched) > 0 {
+		return matched[0], nil
+	}
+	return Voice{}, fmt.Errorf("no voice for locale %q: %w", locale, ErrVoiceNotFound)
+}
+
+// SuggestVoices returns up to n short names that are closest to name, for
+// "did you mean" messages. A name matches the full short name, e.g.
+// "en-US-AvaNeurl", or just the speaker, e.g. "xiaoxiao".
+func SuggestVoices(voices []Voice, name string, n int) []string {
+	query := strings.ToLower(strings.TrimSpace(name))
+	if query == "" || n <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		name  string
+		score int
+	}
+	var candidates []candidate
+	for _, v := range voices {
+		short := strings.ToLower(v.ShortName)
+		speaker := strings.ToLower(voiceSpeaker(v))
+		score := min(editDistance(query, short), editDistance(query, speaker))
+		if strings.Contains(short, query) {
+			// "xiaoxiao" or "en-US-Ava" are parts of the name, rank them first
+			score = 0
+		}
+		// Allow about one typo per three characters
+		if score <= max(2, len([]rune(query))/3) {
+			candidates = append(candidates, candidate{v.ShortName, score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	var names []string
+	for _, c := range candidates {
+		if len(names) == n {
+			break
+		}
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// voiceSpeaker returns the speaker part of a short name, e.g. "Xiaoxiao"
+// for "zh-CN-XiaoxiaoNeural"
+func voiceSpeaker(v Voice) string {
+	speaker := v.ShortName
+	if i := strings.LastIndex(speaker, "-"); i >= 0 {
+		speaker = speaker[i+1:]
+	}
+	return strings.TrimSuffix(speaker, "Neural")
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,131 @@
+package edge_tts
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testVoices 是测试用的声音列表
+var testVoices = []Voice{
+	{ShortName: "en-US-AriaNeural", Locale: "en-US", Gender: "Female", StyleList: []string{"cheerful", "sad"},
+		VoiceTag: VoiceTag{ContentCategories: []string{"News", "Novel"}, VoicePersonalities: []string{"Positive", "Confident"}}},
+	{ShortName: "en-US-AvaMultilingualNeural", Locale: "en-US", Gender: "Female",
+		VoiceTag: VoiceTag{ContentCategories: []string{"Conversation"}, VoicePersonalities: []string{"Friendly"}}},
+	{ShortName: "en-US-AvaNeural", Locale: "en-US", Gender: "Female"},
+	{ShortName: "en-GB-RyanNeural", Locale: "en-GB", Gender: "Male"},
+	{ShortName: "zh-CN-XiaoxiaoNeural", Locale: "zh-CN", Gender: "Female", StyleList: []string{"cheerful"}},
+	{ShortName: "zh-CN-YunxiNeural", Locale: "zh-CN", Gender: "Male"},
+	{ShortName: "is-IS-GudrunNeural", Locale: "is-IS", Gender: "Female"},
+}
+
+// voiceNames 返回声音的短名称
+func voiceNames(voices []Voice) []string {
+	var names []string
+	for _, v := range voices {
+		names = append(names, v.ShortName)
+	}
+	return names
+}
+
+// TestFilterVoices 测试按条件筛选声音
+func TestFilterVoices(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"locale=en-us", []string{"en-US-AriaNeural", "en-US-AvaMultilingualNeural", "en-US-AvaNeural"}},
+		{"language=en,gender=male", []string{"en-GB-RyanNeural"}},
+		{"style=Cheerful", []string{"en-US-AriaNeural", "zh-CN-XiaoxiaoNeural"}},
+		{"multilingual", []string{"en-US-AvaMultilingualNeural"}},
+		{"category=news, personality=confident", []string{"en-US-AriaNeural"}},
+		{"lang=fr", nil},
+	}
+	for _, tt := range tests {
+		f, err := ParseVoiceFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("ParseVoiceFilter(%q) error = %v", tt.filter, err)
+		}
+		if got := voiceNames(FilterVoices(testVoices, f)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FilterVoices(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+	if _, err := ParseVoiceFilter("age=30"); err == nil {
+		t.Error("ParseVoiceFilter(\"age=30\") want error")
+	}
+}
+
+// TestVoiceFilterCheck 测试在没有风格和标签的声音列表上使用这些筛选条件时报错
+func TestVoiceFilterCheck(t *testing.T) {
+	embedded := EmbeddedVoices()
+	for _, spec := range []string{"style=cheerful", "category=news", "personality=friendly"} {
+		f, _ := ParseVoiceFilter(spec)
+		if err := f.Check(embedded); !errors.Is(err, ErrNoVoiceMetadata) {
+			t.Errorf("Check(%q) on the embedded list = %v, want ErrNoVoiceMetadata", spec, err)
+		}
+		if err := f.Check(testVoices); err != nil {
+			t.Errorf("Check(%q) = %v, want nil", spec, err)
+		}
+	}
+	f, _ := ParseVoiceFilter("language=en,gender=female,multilingual")
+	if err := f.Check(embedded); err != nil {
+		t.Errorf("Check() on the embedded list = %v, want nil", err)
+	}
+}
+
+// TestFindVoice 测试查找声音和找不到时的建议
+func TestFindVoice(t *testing.T) {
+	v, err := FindVoice(testVoices, "zh-cn-xiaoxiaoneural")
+	if err != nil || v.ShortName != "zh-CN-XiaoxiaoNeural" {
+		t.Errorf("FindVoice() = %v, %v, want zh-CN-XiaoxiaoNeural", v.ShortName, err)
+	}
+
+	_, err = FindVoice(testVoices, "en-US-AvaNeurl")
+	var notFound *VoiceNotFoundError
+	if !errors.As(err, &notFound) || !errors.Is(err, ErrVoiceNotFound) {
+		t.Fatalf("FindVoice() error = %v, want a VoiceNotFoundError", err)
+	}
+	if len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "en-US-AvaNeural" {
+		t.Errorf("Suggestions = %v, want en-US-AvaNeural first", notFound.Suggestions)
+	}
+}
+
+// TestSuggestVoices 测试模糊匹配声音名称
+func TestSuggestVoices(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"xiaoxiao", []string{"zh-CN-XiaoxiaoNeural"}},
+		{"Yunxy", []string{"zh-CN-YunxiNeural"}},
+		{"en-US-Ava", []string{"en-US-AvaMultilingualNeural", "en-US-AvaNeural"}},
+		{"completely-unknown", nil},
+	}
+	for _, tt := range tests {
+		if got := SuggestVoices(testVoices, tt.name, 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestVoices(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDefaultVoiceForLocale 测试选择语言的默认声音
+func TestDefaultVoiceForLocale(t *testing.T) {
+	tests := []struct {
+		locale, want string
+	}{
+		{"en-US", "en-US-AvaNeural"},
+		{"en", "en-US-AvaNeural"},
+		{"zh-cn", "zh-CN-XiaoxiaoNeural"},
+		{"en-GB", "en-GB-RyanNeural"},
+		{"is", "is-IS-GudrunNeural"},
+	}
+	for _, tt := range tests {
+		v, err := DefaultVoiceForLocale(testVoices, tt.locale)
+		if err != nil || v.ShortName != tt.want {
+			t.Errorf("DefaultVoiceForLocale(%q) = %q, %v, want %q", tt.locale, v.ShortName, err, tt.want)
+		}
+	}
+	if _, err := DefaultVoiceForLocale(testVoices, "fr-FR"); !errors.Is(err, ErrVoiceNotFound) {
+		t.Errorf("DefaultVoiceForLocale(fr-FR) error = %v, want ErrVoiceNotFound", err)
+	}
+}
@@ -0,0 +1,97 @@
+package edge_tts
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestVoiceJSON 测试读取声音列表的全部字段，未知字段保留在 Extra 中
+func TestVoiceJSON(t *testing.T) {
+	data := `{"Name":"Microsoft Server Speech Text to Speech Voice (en-US, AvaMultilingualNeural)",
+		"ShortName":"en-US-AvaMultilingualNeural","Gender":"Female","Locale":"en-US",
+		"SuggestedCodec":"audio-24khz-48kbitrate-mono-mp3",
+		"FriendlyName":"Microsoft AvaMultilingual Online (Natural) - English (United States)",
+		"Status":"GA","SecondaryLocaleList":["de-DE","fr-FR"],
+		"VoiceTag":{"ContentCategories":["Conversation"],"VoicePersonalities":["Caring"]},
+		"WordsPerMinute":"150","RolePlayList":["Narrator"]}`
+
+	var v Voice
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if v.Status != "GA" || v.SuggestedCodec != "audio-24khz-48kbitrate-mono-mp3" || len(v.SecondaryLocaleList) != 2 ||
+		!strings.HasPrefix(v.FriendlyName, "Microsoft AvaMultilingual") {
+		t.Errorf("voice = %+v, want all known fields", v)
+	}
+	if len(v.Extra) != 2 || string(v.Extra["WordsPerMinute"]) != `"150"` {
+		t.Errorf("Extra = %v, want WordsPerMinute and RolePlayList", v.Extra)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var back map[string]json.RawMessage
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if string(back["RolePlayList"]) != `["Narrator"]` || string(back["Status"]) != `"GA"` {
+		t.Errorf("Marshal() = %s, want the extra and known fields", out)
+	}
+}
+
+// TestVoiceLocale 测试从地区解析语言和区域
+func TestVoiceLocale(t *testing.T) {
+	tests := []struct {
+		locale, language, region string
+		name, native             string
+	}{
+		{"en-US", "en", "US", "English (United States)", "English (United States)"},
+		{"de-AT", "de", "AT", "German (Austria)", "Deutsch (Österreich)"},
+		{"zh-CN-liaoning", "zh", "CN", "Chinese (China, Liaoning)", "中文（中国，辽宁）"},
+		{"iu-Latn-CA", "iu", "CA", "Inuktitut (Latin, Canada)", "Inuktitut (Kanata)"},
+		{"es-419", "es", "419", "Spanish (419)", "Spanish (419)"},
+		{"xx-YY", "xx", "YY", "xx (YY)", "xx (YY)"},
+	}
+	for _, tt := range tests {
+		v := Voice{Locale: tt.locale}
+		if v.Language() != tt.language || v.Region() != tt.region {
+			t.Errorf("%s: Language(), Region() = %q, %q, want %q, %q", tt.locale, v.Language(), v.Region(), tt.language, tt.region)
+		}
+		if v.LocaleName() != tt.name || v.NativeLocaleName() != tt.native {
+			t.Errorf("%s: LocaleName(), NativeLocaleName() = %q, %q, want %q, %q", tt.locale, v.LocaleName(), v.NativeLocaleName(), tt.name, tt.native)
+		}
+	}
+	if got := LocaleNativeName("ja"); got != "日本語" {
+		t.Errorf("LocaleNativeName(ja) = %q, want 日本語", got)
+	}
+}
+
+// TestLocaleNamesCoverVoices 测试内置声音列表的每个地区都有名称
+func TestLocaleNamesCoverVoices(t *testing.T) {
+	for _, v := range EmbeddedVoices() {
+		if _, ok := regionNames[v.Region()]; !ok {
+			t.Errorf("%s: no English name for region %q", v.ShortName, v.Region())
+		}
+		if _, ok := languageNames[v.Language()]; !ok {
+			t.Errorf("%s: no name for language %q", v.ShortName, v.Language())
+		}
+		if _, ok := nativeLocaleNames[v.Locale]; !ok {
+			t.Errorf("%s: no native name for %s", v.ShortName, v.Locale)
+		}
+	}
+}
+
+// TestIsMultilingual 测试识别多语言声音
+func TestIsMultilingual(t *testing.T) {
+	if !(Voice{ShortName: "en-US-AvaMultilingualNeural"}).IsMultilingual() {
+		t.Error("AvaMultilingual is not multilingual")
+	}
+	if !(Voice{ShortName: "en-US-JennyNeural", SecondaryLocaleList: []string{"fr-FR"}}).IsMultilingual() {
+		t.Error("a voice with secondary locales is not multilingual")
+	}
+	if (Voice{ShortName: "en-US-AvaNeural"}).IsMultilingual() {
+		t.Error("AvaNeural is multilingual")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,10 @@ import (
 	"strings"
 )
 
+// errNotModified is returned by fetchVoices when the list has not changed
+// since the ETag given
+var errNotModified = errors.New("voice list not modified")
+
 // ListVoices gets all available voices
 func ListVoices(ctx context.Context) ([]Voice, error) {
 	// Get system proxy
@@ -20,7 +25,26 @@ func ListVoices(ctx context.Context) ([]Voice, error) {
 		proxy = os.Getenv("HTTPS_PROXY")
 	}
 
-	// Create HTTP client
+	return ListVoicesWithProxy(ctx, proxy)
+}
+
+// ListVoicesWithProxy gets all available voices using a proxy
+func ListVoicesWithProxy(ctx context.Context, proxyURL string) ([]Voice, error) {
+	profile, tokens, err := LoadClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := voiceListClient(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	voices, _, err := fetchVoices(ctx, client, profile.VoiceListURL(), "", profile, tokens)
+	return voices, err
+}
+
+// voiceListClient creates the HTTP client for the voice list, using proxy
+// if it is not empty
+func voiceListClient(proxy string) (*http.Client, error) {
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
@@ -36,131 +60,80 @@ func ListVoices(ctx context.Context) ([]Voice, error) {
 		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
-	client := &http.Client{
+	return &http.Client{
 		Transport: transport,
-	}
-
-	// Generate security token
-	secMsGec := generateSecMsGec()
-
-	// Build request URL
-	reqURL := fmt.Sprintf("%s&Sec-MS-GEC=%s&Sec-MS-GEC-Version=%s",
-		VoiceList, secMsGec, SEC_MS_GEC_VERSION)
-
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request failed: %w", err)
-	}
-
-	// Set request headers
-	for k, v := range BaseHeaders {
-		req.Header.Set(k, v)
-	}
+	}, nil
+}
 
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check response status code
-	if resp.StatusCode != http.StatusOK {
-		// If 403 error, may need to adjust clock skew
-		if resp.StatusCode == http.StatusForbidden {
-			if err := handleClientResponseError(resp); err != nil {
-				return nil, err
-			}
-			// Retry request
-			return ListVoices(ctx)
+// fetchVoices downloads the voice list from listURL. If etag is not empty
+// the request is conditional and errNotModified is returned when the list
+// has not changed. The ETag of the response is returned with the voices.
+// The request is signed by tokens and carries the headers of profile.
+func fetchVoices(ctx context.Context, client *http.Client, listURL, etag string, profile ClientProfile, tokens TokenProvider) ([]Voice, string, error) {
+	// The built-in token follows the server clock reported on a 403
+	clock := &serverClock{}
+	if p, ok := tokens.(SecMsGecTokenProvider); ok && p.Clock == nil {
+		tokens = SecMsGecTokenProvider{Clock: clock}
+	}
+	// Only one retry after the clock skew was adjusted
+	for retried := false; ; retried = true {
+		// Generate security token
+		secMsGec, err := tokens.Token(ctx, profile)
+		if err != nil {
+			return nil, "", err
 		}
-		return nil, fmt.Errorf("request failed with status: %d", resp.StatusCode)
-	}
 
-	// Read response content
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %w", err)
-	}
+		// Build request URL
+		reqURL := fmt.Sprintf("%s&Sec-MS-GEC=%s&Sec-MS-GEC-Version=%s",
+			listURL, secMsGec, profile.GecVersion())
 
-	// Parse response
-	var voices []Voice
-	if err := json.Unmarshal(body, &voices); err != nil {
-		return nil, fmt.Errorf("parse response failed: %w", err)
-	}
-
-	// Clean whitespace in voice tags
-	for i := range voices {
-		for j := range voices[i].VoiceTag.ContentCategories {
-			voices[i].VoiceTag.ContentCategories[j] = strings.TrimSpace(voices[i].VoiceTag.ContentCategories[j])
-		}
-		for j := range voices[i].VoiceTag.VoicePersonalities {
-			voices[i].VoiceTag.VoicePersonalities[j] = strings.TrimSpace(voices[i].VoiceTag.VoicePersonalities[j])
+		// Create request
+		req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("create request failed: %w", err)
 		}
-	}
-
-	return voices, nil
-}
 
-// ListVoicesWithProxy gets all available voices using a proxy
-func ListVoicesWithProxy(ctx context.Context, proxyURL string) ([]Voice, error) {
-	// Parse proxy URL
-	proxy, err := url.Parse(proxyURL)
-	if err != nil {
-		return nil, fmt.Errorf("parse proxy URL failed: %w", err)
-	}
-
-	// Create HTTP client
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-			Proxy: http.ProxyURL(proxy),
-		},
-	}
-
-	// Generate security token
-	secMsGec := generateSecMsGec()
-
-	// Build request URL
-	reqURL := fmt.Sprintf("%s&Sec-MS-GEC=%s&Sec-MS-GEC-Version=%s",
-		VoiceList, secMsGec, SEC_MS_GEC_VERSION)
-
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request failed: %w", err)
-	}
+		// Set request headers
+		req.Header = profile.Header()
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
 
-	// Set request headers
-	for k, v := range BaseHeaders {
-		req.Header.Set(k, v)
-	}
+		// Send request
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("request failed: %w", err)
+		}
 
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check response status code
-	if resp.StatusCode != http.StatusOK {
-		// If 403 error, may need to adjust clock skew
-		if resp.StatusCode == http.StatusForbidden {
-			if err := handleClientResponseError(resp); err != nil {
-				return nil, err
+		switch resp.StatusCode {
+		case http.StatusOK:
+			voices, err := readVoices(resp.Body)
+			resp.Body.Close()
+			return voices, resp.Header.Get("ETag"), err
+		case http.StatusNotModified:
+			resp.Body.Close()
+			return nil, etag, errNotModified
+		case http.StatusForbidden:
+			// If 403 error, may need to adjust clock skew
+			err := handleClientResponseError(resp, clock)
+			resp.Body.Close()
+			if err != nil {
+				return nil, "", err
 			}
-			// Retry request
-			return ListVoicesWithProxy(ctx, proxyURL)
+			if !retried {
+				continue
+			}
+		default:
+			resp.Body.Close()
 		}
-		return nil, fmt.Errorf("request failed with status: %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("request failed with status: %d", resp.StatusCode)
 	}
+}
 
+// readVoices parses a voice list response
+func readVoices(r io.Reader) ([]Voice, error) {
 	// Read response content
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("read response body failed: %w", err)
 	}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bytectlgo/edge-tts/pkg/edge_tts"
+)
+
+const previewUsage = `Usage: edge-tts preview [flags]
+
+Synthesizes a sample clip for every voice that passes the filter into a
+directory, with an index.html that lists the voices and plays the clips:
+
+  edge-tts preview -locale en-GB -dir previews
+  edge-tts preview -voice-filter "language=zh,style=cheerful" -text "今天天气真好"
+
+Without -text each voice reads a sample sentence in its own language. A
+filter is required, use -all to preview every voice.
+
+Flags:
+`
+
+// runPreview runs the "preview" subcommand
+func runPreview(args []string) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, previewUsage)
+		fs.PrintDefaults()
+	}
+	voiceFilter := fs.String("voice-filter", "", "Only preview voices matching key=value pairs: locale, language, gender, style, category, personality, multilingual")
+	locale := fs.String("locale", "", "Only preview voices of this locale (en-US) or language (en)")
+	gender := fs.String("gender", "", "Only preview voices of this gender: Female or Male")
+	style := fs.String("style", "", "Only preview voices that support this speaking style")
+	all := fs.Bool("all", false, "Preview every voice")
+	text := fs.String("text", "", "Sentence every voice reads, a sample in the voice's language by default")
+	dir := fs.String("dir", "preview", "Directory for the clips and index.html")
+	concurrency := fs.Int("concurrency", edge_tts.DefaultPreviewConcurrency, "Number of voices synthesized at once")
+	timeout := fs.Duration("timeout", edge_tts.DefaultPreviewClipTimeout, "Time limit for each voice, 0 for no limit")
+	rate := fs.String("rate", "+0%", "Speech rate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("-concurrency must be at least 1")
+	}
+	if *timeout < 0 {
+		return fmt.Errorf("-timeout must not be negative")
+	}
+
+	filter, err := voiceFilterFlags(*voiceFilter, *locale, *gender, *style)
+	if err != nil {
+		return err
+	}
+	if filter == (edge_tts.VoiceFilter{}) && !*all {
+		fs.Usage()
+		return fmt.Errorf("no voice filter, use -all to preview every voice")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	voices, source := edge_tts.CachedVoices(ctx)
+	cancel()
+	if source == edge_tts.VoiceSourceEmbedded {
+		fmt.Fprintln(os.Stderr, "Warning: offline, using the built-in voice list")
+	}
+	if err := filter.Check(voices); err != nil {
+		return fmt.Errorf("%w, connect to the internet once to download the full list", err)
+	}
+	voices = edge_tts.FilterVoices(voices, filter)
+	if len(voices) == 0 {
+		return fmt.Errorf("no voice matches the filter")
+	}
+
+	fmt.Printf("Previewing %d voices into %s\n", len(voices), *dir)
+	p := edge_tts.NewPreview(voices, *text, edge_tts.WithRate(*rate), edge_tts.WithMUID(edge_tts.NewMUID()))
+	p.Concurrency = *concurrency
+	p.ClipTimeout = *timeout
+	clips, err := p.Save(context.Background(), *dir)
+	if err != nil {
+		return fmt.Errorf("Failed to save preview: %v", err)
+	}
+
+	failed := 0
+	for _, clip := range clips {
+		if clip.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Warning: %s failed: %v\n", clip.Voice.ShortName, clip.Err)
+		}
+	}
+	fmt.Printf("%d clips saved, open %s\n", len(clips)-failed, filepath.Join(*dir, edge_tts.PreviewIndexFile))
+	if failed == len(clips) {
+		return fmt.Errorf("every voice failed")
+	}
+	return nil
+}
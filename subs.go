@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bytectlgo/edge-tts/pkg/edge_tts"
+)
+
+const subsUsage = `Usage: edge-tts subs <command> [flags] files...
+
+Commands:
+  shift    -by 1.5s -o out.srt in.srt         Move all cues (negative values move earlier)
+  concat   [-offsets 0s,30s] [-gap 0s] [-audio a.mp3,b.mp3] -o out.srt a.srt b.vtt
+                                             Join files one after another
+  merge    -o out.srt a.srt b.srt             Interleave tracks by start time
+  convert  -o out.vtt in.srt                  Renumber and convert
+
+concat starts each file where the audio of the previous one ends, so its
+trailing silence is kept. The audio is given with -audio, or found next to
+the subtitles with the same name and .mp3; without audio the next file
+starts at the last cue.
+
+Input files are SRT or WebVTT. The output format follows the -o extension
+(.vtt, .ass, .lrc or SRT); without -o SRT is written to stdout.
+`
+
+// runSubs runs the "subs" subcommand
+func runSubs(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, subsUsage)
+		return fmt.Errorf("missing subs command")
+	}
+
+	command := args[0]
+	fs := flag.NewFlagSet("subs "+command, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, subsUsage) }
+	output := fs.String("o", "", "Output subtitle filename")
+	by := fs.Duration("by", 0, "Time to shift by (shift)")
+	offsets := fs.String("offsets", "", "Comma-separated start time of each file (concat)")
+	gap := fs.Duration("gap", 0, "Gap after the previous file when -offsets is not given (concat)")
+	audio := fs.String("audio", "", "Comma-separated audio file of each input, whose length sets where the next file starts (concat)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	tracks := make([]*edge_tts.SubMaker, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		track, err := edge_tts.LoadSubtitles(path)
+		if err != nil {
+			return fmt.Errorf("Failed to load subtitles %s: %v", path, err)
+		}
+		tracks = append(tracks, track)
+	}
+	if len(tracks) == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	var result *edge_tts.SubMaker
+	switch command {
+	case "shift", "convert":
+		if len(tracks) != 1 {
+			return fmt.Errorf("%s takes one input file", command)
+		}
+		result = tracks[0]
+		result.Shift(*by)
+		result.Renumber()
+	case "concat":
+		starts, err := parseOffsets(*offsets, len(tracks))
+		if err != nil {
+			return err
+		}
+		durations, err := audioDurations(*audio, fs.Args())
+		if err != nil {
+			return err
+		}
+		result = edge_tts.NewSubMaker()
+		var end time.Duration
+		for i, track := range tracks {
+			start := end + *gap
+			if i == 0 {
+				start = 0
+			}
+			if starts != nil {
+				start = starts[i]
+			}
+			result.Append(track, start)
+			// The audio may run on after the last cue
+			end = max(result.End(), start+durations[i])
+		}
+	case "merge":
+		result = edge_tts.MergeTracks(tracks...)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown subs command %q", command)
+	}
+
+	if *output == "" {
+		fmt.Print(result.GetSRT())
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(result.GetSubtitles(*output)), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Subtitles saved to %s\n", *output)
+	return nil
+}
+
+// audioDurations returns the length of the audio of each subtitle file,
+// from the -audio list or the .mp3 file next to it, 0 when it is unknown
+func audioDurations(value string, paths []string) ([]time.Duration, error) {
+	files := make([]string, len(paths))
+	if value != "" {
+		files = strings.Split(value, ",")
+		if len(files) != len(paths) {
+			return nil, fmt.Errorf("expected %d audio files, got %d", len(paths), len(files))
+		}
+	}
+
+	durations := make([]time.Duration, len(paths))
+	for i, path := range paths {
+		file := strings.TrimSpace(files[i])
+		if value == "" {
+			file = strings.TrimSuffix(path, filepath.Ext(path)) + ".mp3"
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if value == "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("Failed to read audio: %v", err)
+		}
+		durations[i] = edge_tts.MP3Duration(data)
+	}
+	return durations, nil
+}
+
+// parseOffsets parses the -offsets list, nil when it is empty
+func parseOffsets(value string, n int) ([]time.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d offsets, got %d", n, len(parts))
+	}
+	offsets := make([]time.Duration, n)
+	for i, part := range parts {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid offset: %v", err)
+		}
+		offsets[i] = d
+	}
+	return offsets, nil
+}
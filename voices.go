@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bytectlgo/edge-tts/pkg/edge_tts"
+)
+
+const voicesUsage = `Usage: edge-tts voices <command> [flags]
+
+Commands:
+  update   [-o voices.json]   Refresh the cached voice list (conditional on its ETag)
+  path                        Print the cache file
+  diff     [-json] [-against new.json] old.json
+                              Compare a stored voice list with the live one
+
+-list-voices and voice checks use the cached list for a day before checking
+for changes, and the list built into edge-tts when offline. With -o update
+also writes the list as a JSON array.
+
+diff reports added, removed and changed voices. It exits with 0 when the
+lists are the same, 1 when they differ and 2 on errors, like diff(1), so it
+can alert from cron:
+
+  edge-tts voices diff voices.json || mail -s "Voices changed" me@example.com
+`
+
+// runVoices runs the "voices" subcommand
+func runVoices(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, voicesUsage)
+		return fmt.Errorf("missing voices command")
+	}
+
+	command := args[0]
+	fs := flag.NewFlagSet("voices "+command, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, voicesUsage) }
+	output := fs.String("o", "", "Also write the voice list to this file (update)")
+	jsonOutput := fs.Bool("json", false, "Print the differences as JSON (diff)")
+	against := fs.String("against", "", "Compare with this voice list instead of the live one (diff)")
+	if err := fs.Parse(args[1:]); err != nil {
+		if command == "diff" {
+			return exitCode(2)
+		}
+		return err
+	}
+
+	if command == "diff" {
+		if fs.NArg() != 1 {
+			fs.Usage()
+			fmt.Fprintln(os.Stderr, "diff needs the stored voice list")
+			return exitCode(2)
+		}
+		changed, err := diffVoices(fs.Arg(0), *against, *jsonOutput)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitCode(2)
+		}
+		if changed {
+			return exitCode(1)
+		}
+		return nil
+	}
+
+	cache, err := edge_tts.NewVoiceCache()
+	if err != nil {
+		return fmt.Errorf("No cache directory: %v", err)
+	}
+
+	switch command {
+	case "update":
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		voices, modified, err := cache.Update(ctx)
+		switch {
+		case errors.Is(err, edge_tts.ErrVoiceCacheNotSaved):
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		case err != nil:
+			return fmt.Errorf("Failed to update voice list: %v", err)
+		case modified:
+			fmt.Printf("Voice list updated, %d voices cached in %s\n", len(voices), cache.Path)
+		default:
+			fmt.Printf("Voice list is up to date, %d voices cached in %s\n", len(voices), cache.Path)
+		}
+		if *output != "" {
+			data, err := json.MarshalIndent(voices, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(*output, append(data, '\n'), 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Voice list saved to %s\n", *output)
+		}
+	case "path":
+		fmt.Println(cache.Path)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown voices command %q", command)
+	}
+	return nil
+}
+
+// exitCode is returned by a subcommand to exit with the code without
+// printing anything more
+type exitCode int
+
+func (e exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// diffVoices prints the differences between the voice list in oldPath and
+// the one in newPath, or the live list when newPath is empty, and reports
+// whether there are any
+func diffVoices(oldPath, newPath string, jsonOutput bool) (bool, error) {
+	old, err := edge_tts.ReadVoiceFile(oldPath)
+	if err != nil {
+		return false, fmt.Errorf("Failed to read voice list: %v", err)
+	}
+
+	var current []edge_tts.Voice
+	if newPath != "" {
+		current, err = edge_tts.ReadVoiceFile(newPath)
+		if err != nil {
+			return false, fmt.Errorf("Failed to read voice list: %v", err)
+		}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		current, err = edge_tts.ListVoices(ctx)
+		if err != nil {
+			return false, fmt.Errorf("Failed to get voice list: %v", err)
+		}
+	}
+
+	diff := edge_tts.DiffVoices(old, current)
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return false, err
+		}
+		return !diff.Empty(), nil
+	}
+
+	for _, v := range diff.Added {
+		fmt.Printf("+ %s (%s, %s)\n", v.ShortName, v.Locale, v.Gender)
+	}
+	for _, v := range diff.Removed {
+		fmt.Printf("- %s (%s, %s)\n", v.ShortName, v.Locale, v.Gender)
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s\n", change.ShortName)
+		for _, f := range change.Fields {
+			fmt.Printf("    %s: %q -> %q\n", f.Field, f.Old, f.New)
+		}
+	}
+	fmt.Printf("%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return !diff.Empty(), nil
+}
+
+// checkVoice warns when voice is not in the voice list, with the closest names
+func checkVoice(voice string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	voices, source := edge_tts.CachedVoices(ctx)
+	if _, err := edge_tts.FindVoice(voices, voice); err != nil {
+		if source == edge_tts.VoiceSourceEmbedded {
+			fmt.Fprintf(os.Stderr, "Warning: %v (offline, the built-in voice list may be outdated)\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// voiceFilterFlags combines -voice-filter with the -locale, -gender and
+// -style shortcuts. locale is a locale such as en-US or a bare language
+// such as en.
+func voiceFilterFlags(spec, locale, gender, style string) (edge_tts.VoiceFilter, error) {
+	filter, err := edge_tts.ParseVoiceFilter(spec)
+	if err != nil {
+		return filter, err
+	}
+	if strings.Contains(locale, "-") {
+		filter.Locale = locale
+	} else if locale != "" {
+		filter.Language = locale
+	}
+	if gender != "" {
+		filter.Gender = gender
+	}
+	if style != "" {
+		filter.Style = style
+	}
+	return filter, nil
+}
+
+// listVoices prints the voices that pass the filter in the output format:
+// table, json, csv or markdown (the format of voice-list.md)
+func listVoices(filter edge_tts.VoiceFilter, output string) error {
+	write, ok := voiceWriters[output]
+	if !ok {
+		return fmt.Errorf("unknown output format %q, expected table, json, csv or markdown", output)
+	}
+
+	// Create context
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Use the cached voice list, or the built-in one when offline
+	voices, source := edge_tts.CachedVoices(ctx)
+	if source == edge_tts.VoiceSourceEmbedded {
+		fmt.Fprintln(os.Stderr, "Warning: offline, showing the built-in voice list")
+	}
+	if err := filter.Check(voices); err != nil {
+		return fmt.Errorf("%w, connect to the internet once to download the full list", err)
+	}
+	return write(os.Stdout, edge_tts.FilterVoices(voices, filter))
+}
+
+// voiceWriters write a voice list in each -output format
+var voiceWriters = map[string]func(io.Writer, []edge_tts.Voice) error{
+	"table":    writeVoiceTable,
+	"json":     writeVoiceJSON,
+	"csv":      writeVoiceCSV,
+	"markdown": writeVoiceMarkdown,
+}
+
+// voiceColumns are the columns of the table and CSV output
+var voiceColumns = []string{"Name", "Gender", "Locale", "ContentCategories", "VoicePersonalities", "Styles"}
+
+// voiceRow returns the columns of one voice
+func voiceRow(v edge_tts.Voice) []string {
+	return []string{
+		v.ShortName,
+		v.Gender,
+		v.Locale,
+		strings.Join(v.VoiceTag.ContentCategories, ", "),
+		strings.Join(v.VoiceTag.VoicePersonalities, ", "),
+		strings.Join(v.StyleList, ", "),
+	}
+}
+
+func writeVoiceTable(w io.Writer, voices []edge_tts.Voice) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(voiceColumns, "\t"))
+	for _, v := range voices {
+		fmt.Fprintln(tw, strings.Join(voiceRow(v), "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeVoiceJSON(w io.Writer, voices []edge_tts.Voice) error {
+	if voices == nil {
+		voices = []edge_tts.Voice{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(voices)
+}
+
+func writeVoiceCSV(w io.Writer, voices []edge_tts.Voice) error {
+	cw := csv.NewWriter(w)
+	cw.Write(voiceColumns)
+	for _, v := range voices {
+		cw.Write(voiceRow(v))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeVoiceMarkdown writes the layout of voice-list.md, so the file can be
+// regenerated with -output markdown
+func writeVoiceMarkdown(w io.Writer, voices []edge_tts.Voice) error {
+	escape := strings.NewReplacer("|", `\|`)
+	fmt.Fprintln(w, "| Name | Gender | ContentCategories | VoicePersonalities |")
+	fmt.Fprintln(w, "|------|--------|------------------|-------------------|")
+	for _, v := range voices {
+		row := []string{
+			v.ShortName,
+			v.Gender,
+			strings.Join(v.VoiceTag.ContentCategories, ", "),
+			strings.Join(v.VoiceTag.VoicePersonalities, ", "),
+		}
+		for i := range row {
+			row[i] = escape.Replace(row[i])
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}